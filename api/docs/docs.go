@@ -26,9 +26,15 @@ const docTemplate = `{
     "paths": {
         "/v1alpha1/canvases": {
             "get": {
-                "description": "List all the canvases the user has access to",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List all the canvases the user has access to. With watch=true\nthe response is a text/event-stream of ADDED, MODIFIED and\nDELETED events whose data is a Canvas and whose id is the\nresource version to resume from.",
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "text/event-stream"
                 ],
                 "tags": [
                     "Canvas"
@@ -36,6 +42,12 @@ const docTemplate = `{
                 "summary": "List all canvases",
                 "operationId": "ListCanvasesV1alpha1",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Continue is the cursor returned by the previous page. It cannot be\ncombined with offset.",
+                        "name": "continue",
+                        "in": "query"
+                    },
                     {
                         "maximum": 100,
                         "minimum": 1,
@@ -50,6 +62,55 @@ const docTemplate = `{
                         "description": "Offset is the number of items to skip.",
                         "name": "offset",
                         "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "LabelSelector restricts the list to canvases whose labels match, e.g. \"team=payments\".",
+                        "name": "labelSelector",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "Ready",
+                            "Provisioning",
+                            "Failed"
+                        ],
+                        "type": "string",
+                        "description": "Phase restricts the list to canvases in the given phase.",
+                        "name": "phase",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Q is matched case-insensitively against the name and display name.",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "name",
+                            "-name",
+                            "createdAt",
+                            "-createdAt",
+                            "displayName",
+                            "-displayName"
+                        ],
+                        "type": "string",
+                        "description": "Sort orders the list by a field. A leading dash sorts in descending order.",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ResourceVersion resumes a watch after the given version. The\nLast-Event-ID header is used when it is not set.",
+                        "name": "resourceVersion",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Watch streams ADDED, MODIFIED and DELETED events as Server-Sent Events\ninstead of returning a list.",
+                        "name": "watch",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -61,6 +122,30 @@ const docTemplate = `{
                     },
                     "400": {
                         "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ValidationErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "410": {
+                        "description": "Gone",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
@@ -74,7 +159,12 @@ const docTemplate = `{
                 }
             },
             "post": {
-                "description": "Create a new canvas with the given display name",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create a new canvas with the given display name.\nRequests sent with an Idempotency-Key can be safely retried: a repeated request gets the response of\nthe first one, marked with the Idempotent-Replayed header.",
                 "consumes": [
                     "application/json"
                 ],
@@ -87,6 +177,12 @@ const docTemplate = `{
                 "summary": "Create a new canvas",
                 "operationId": "CreateCanvasV1alpha1",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Unique key making retries of the request safe",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
                     {
                         "description": "Canvas data",
                         "name": "canvas",
@@ -98,20 +194,496 @@ const docTemplate = `{
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/Canvas"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Resource version of the canvas"
+                            },
+                            "Idempotent-Replayed": {
+                                "type": "string",
+                                "description": "Set to true when the response is replayed for a repeated Idempotency-Key"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ValidationErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/ValidationErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1alpha1/canvases/{name}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get a single canvas by name. The response carries the resource version of the canvas as its ETag,\nwhich can be sent in If-None-Match to skip unchanged canvases.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Canvas"
+                ],
+                "summary": "Get a canvas",
+                "operationId": "GetCanvasV1alpha1",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Canvas name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the client's copy of the canvas",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/Canvas"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Resource version of the canvas"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Not Modified"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Replace the mutable fields of an existing canvas",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Canvas"
+                ],
+                "summary": "Replace a canvas",
+                "operationId": "UpdateCanvasV1alpha1",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Canvas name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETags the canvas must still match one of for the change to apply, or *",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Canvas data",
+                        "name": "canvas",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/UpdateCanvasRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/Canvas"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Resource version of the canvas"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ValidationErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/ValidationErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delete a canvas by name",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Canvas"
+                ],
+                "summary": "Delete a canvas",
+                "operationId": "DeleteCanvasV1alpha1",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Canvas name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETags the canvas must still match one of to be deleted, or *",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Partially update an existing canvas, leaving omitted fields unchanged",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Canvas"
+                ],
+                "summary": "Update a canvas",
+                "operationId": "PatchCanvasV1alpha1",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Canvas name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETags the canvas must still match one of for the change to apply, or *",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Fields to update",
+                        "name": "canvas",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/PatchCanvasRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/Canvas"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Resource version of the canvas"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ValidationErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/ValidationErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/version": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get version and build information about the API server",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "System"
+                ],
+                "summary": "Get the server version",
+                "operationId": "GetVersion",
+                "responses": {
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/Canvas"
+                            "$ref": "#/definitions/Version"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "429": {
+                        "description": "Too Many Requests",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
@@ -121,20 +693,254 @@ const docTemplate = `{
         }
     },
     "definitions": {
+        "AdoptionPolicy": {
+            "type": "string",
+            "enum": [
+                "Refuse",
+                "Adopt",
+                "AdoptAndOwn"
+            ],
+            "x-enum-varnames": [
+                "AdoptionPolicyRefuse",
+                "AdoptionPolicyAdopt",
+                "AdoptionPolicyAdoptAndOwn"
+            ]
+        },
         "Canvas": {
             "type": "object",
             "required": [
+                "createdAt",
                 "id",
-                "name"
+                "name",
+                "namespace",
+                "resourceVersion",
+                "status"
             ],
             "properties": {
+                "adoptionPolicy": {
+                    "description": "AdoptionPolicy selects what happens when the namespace of the Canvas\nalready exists.\n\n+kubebuilder:default=Refuse\n+optional",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/AdoptionPolicy"
+                        }
+                    ]
+                },
+                "createdAt": {
+                    "description": "CreatedAt is the time the canvas was created.",
+                    "type": "string"
+                },
+                "deletionPolicy": {
+                    "description": "DeletionPolicy selects what happens to the namespaces of the Canvas\nwhen it is deleted.\n\n+kubebuilder:default=Delete\n+optional",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/DeletionPolicy"
+                        }
+                    ]
+                },
                 "displayName": {
                     "type": "string"
                 },
+                "homeNamespace": {
+                    "description": "HomeNamespace is the namespace provisioned for the Canvas, which holds\nthe objects managed for it. It defaults to the name of the Canvas and\ncannot be changed. An existing namespace may only be used if it is\nannotated with orray.dev/canvas set to the name of the Canvas.\n\n+optional",
+                    "type": "string"
+                },
                 "id": {
                     "type": "string"
                 },
+                "members": {
+                    "description": "Members are granted access to the Canvas's namespace according to\ntheir role.\n\n+optional\n+listType=atomic",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/CanvasMember"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "namespace": {
+                    "description": "Namespace is the name of the home namespace provisioned for the canvas.",
+                    "type": "string"
+                },
+                "namespaces": {
+                    "description": "Namespaces are the existing namespaces the Canvas spans besides its\nhome namespace. They are tracked, not provisioned.\n\n+optional\n+listType=atomic",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/CanvasNamespace"
+                    }
+                },
+                "network": {
+                    "description": "Network restricts the traffic the workloads of the Canvas accept.\n\n+optional",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/CanvasNetwork"
+                        }
+                    ]
+                },
+                "resourceVersion": {
+                    "description": "ResourceVersion changes on every write to the canvas. It is also sent\nas the ETag of canvas responses.",
+                    "type": "string"
+                },
+                "resources": {
+                    "description": "Resources limits the resources the workloads of the Canvas may use.\n\n+optional",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/CanvasResources"
+                        }
+                    ]
+                },
+                "status": {
+                    "description": "Status summarizes the provisioning state of the canvas.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/CanvasStatus"
+                        }
+                    ]
+                }
+            }
+        },
+        "CanvasMember": {
+            "type": "object",
+            "properties": {
+                "kind": {
+                    "description": "Kind is the kind of the member, one of User, Group or ServiceAccount.\n\n+kubebuilder:validation:Enum=User;Group;ServiceAccount",
+                    "type": "string"
+                },
+                "name": {
+                    "description": "Name is the name of the member.\n\n+kubebuilder:validation:MinLength=1",
+                    "type": "string"
+                },
+                "namespace": {
+                    "description": "Namespace is the namespace of a ServiceAccount member. It defaults to\nthe namespace of the Canvas.\n\n+optional",
+                    "type": "string"
+                },
+                "role": {
+                    "description": "Role is the access granted to the member.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/CanvasRole"
+                        }
+                    ]
+                }
+            }
+        },
+        "CanvasNamespace": {
+            "type": "object",
+            "properties": {
                 "name": {
+                    "description": "Name is the name of a namespace.\n\n+optional",
+                    "type": "string"
+                },
+                "selector": {
+                    "description": "Selector selects the namespaces whose labels match.\n\n+optional",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/LabelSelector"
+                        }
+                    ]
+                }
+            }
+        },
+        "CanvasNetwork": {
+            "type": "object",
+            "properties": {
+                "allowedCanvases": {
+                    "description": "AllowedCanvases are the Canvases whose workloads may reach the\nworkloads of this Canvas when it is isolated. Canvases that do not\nexist are ignored until they are created.\n\n+optional\n+listType=set",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "allowedNamespaces": {
+                    "description": "AllowedNamespaces are the namespaces, such as the one of an ingress\ncontroller, whose workloads may reach the workloads of this Canvas\nwhen it is isolated.\n\n+optional\n+listType=set",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "isolation": {
+                    "description": "Isolation selects which traffic is accepted.\n\n+kubebuilder:default=Open\n+optional",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/NetworkIsolation"
+                        }
+                    ]
+                }
+            }
+        },
+        "CanvasResources": {
+            "type": "object",
+            "properties": {
+                "defaultLimits": {
+                    "description": "DefaultLimits are the resource limits of the containers that do not set\ntheirs.\n\n+optional",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/ResourceList"
+                        }
+                    ]
+                },
+                "defaultRequests": {
+                    "description": "DefaultRequests are the resource requests of the containers that do not\nset theirs.\n\n+optional",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/ResourceList"
+                        }
+                    ]
+                },
+                "quota": {
+                    "description": "Quota is the total amount of each resource the namespace may use, such\nas \"requests.cpu\", \"limits.memory\" or \"pods\".\n\n+optional",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/ResourceList"
+                        }
+                    ]
+                }
+            }
+        },
+        "CanvasRole": {
+            "type": "string",
+            "enum": [
+                "viewer",
+                "editor",
+                "owner"
+            ],
+            "x-enum-varnames": [
+                "CanvasRoleViewer",
+                "CanvasRoleEditor",
+                "CanvasRoleOwner"
+            ]
+        },
+        "CanvasStatus": {
+            "type": "object",
+            "required": [
+                "phase"
+            ],
+            "properties": {
+                "message": {
+                    "description": "Message is the human-readable message of the Ready condition.",
+                    "type": "string"
+                },
+                "namespaces": {
+                    "description": "Namespaces are the namespaces the canvas spans, including its home\nnamespace.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "observedGeneration": {
+                    "description": "ObservedGeneration is the generation of the canvas last reconciled by\nthe controller.",
+                    "type": "integer"
+                },
+                "phase": {
+                    "description": "Phase is derived from the Ready condition of the canvas.",
+                    "type": "string",
+                    "enum": [
+                        "Provisioning",
+                        "Ready",
+                        "Failed"
+                    ]
+                },
+                "reason": {
+                    "description": "Reason is the machine-readable reason of the Ready condition.",
                     "type": "string"
                 }
             }
@@ -154,6 +960,19 @@ const docTemplate = `{
                 }
             }
         },
+        "DeletionPolicy": {
+            "type": "string",
+            "enum": [
+                "Delete",
+                "Orphan",
+                "Retain"
+            ],
+            "x-enum-varnames": [
+                "DeletionPolicyDelete",
+                "DeletionPolicyOrphan",
+                "DeletionPolicyRetain"
+            ]
+        },
         "ErrorResponse": {
             "type": "object",
             "properties": {
@@ -174,6 +993,93 @@ const docTemplate = `{
                 }
             }
         },
+        "FieldError": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "description": "Field is the JSON name of the offending field, e.g. \"displayName\".",
+                    "type": "string"
+                },
+                "jsonPath": {
+                    "description": "JSONPath locates the offending field in the request, e.g. \"$.displayName\".\nIt is empty when the error does not refer to the request body.",
+                    "type": "string"
+                },
+                "message": {
+                    "description": "Message is a human-readable description of the problem.",
+                    "type": "string"
+                },
+                "param": {
+                    "description": "Param is the parameter of the failed validation rule, e.g. \"100\" for \"max=100\".",
+                    "type": "string"
+                },
+                "reason": {
+                    "description": "Reason is a machine-readable description of the problem reported by\nKubernetes, when known.",
+                    "type": "string"
+                },
+                "tag": {
+                    "description": "Tag is the validation rule that failed, e.g. \"required\" or \"max\".",
+                    "type": "string"
+                }
+            }
+        },
+        "LabelSelector": {
+            "type": "object",
+            "properties": {
+                "matchExpressions": {
+                    "description": "matchExpressions is a list of label selector requirements. The requirements are ANDed.\n+optional\n+listType=atomic",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/LabelSelectorRequirement"
+                    }
+                },
+                "matchLabels": {
+                    "description": "matchLabels is a map of {key,value} pairs. A single {key,value} in the matchLabels\nmap is equivalent to an element of matchExpressions, whose key field is \"key\", the\noperator is \"In\", and the values array contains only \"value\". The requirements are ANDed.\n+optional",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "LabelSelectorOperator": {
+            "type": "string",
+            "enum": [
+                "In",
+                "NotIn",
+                "Exists",
+                "DoesNotExist"
+            ],
+            "x-enum-varnames": [
+                "LabelSelectorOpIn",
+                "LabelSelectorOpNotIn",
+                "LabelSelectorOpExists",
+                "LabelSelectorOpDoesNotExist"
+            ]
+        },
+        "LabelSelectorRequirement": {
+            "type": "object",
+            "properties": {
+                "key": {
+                    "description": "key is the label key that the selector applies to.",
+                    "type": "string"
+                },
+                "operator": {
+                    "description": "operator represents a key's relationship to a set of values.\nValid operators are In, NotIn, Exists and DoesNotExist.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/LabelSelectorOperator"
+                        }
+                    ]
+                },
+                "values": {
+                    "description": "values is an array of string values. If the operator is In or NotIn,\nthe values array must be non-empty. If the operator is Exists or DoesNotExist,\nthe values array must be empty. This array is replaced during a strategic\nmerge patch.\n+optional\n+listType=atomic",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
         "ListResponse-Canvas": {
             "type": "object",
             "properties": {
@@ -194,9 +1100,26 @@ const docTemplate = `{
                 }
             }
         },
+        "NetworkIsolation": {
+            "type": "string",
+            "enum": [
+                "Open",
+                "NamespaceIsolated",
+                "DenyAll"
+            ],
+            "x-enum-varnames": [
+                "NetworkIsolationOpen",
+                "NetworkIsolationNamespaceIsolated",
+                "NetworkIsolationDenyAll"
+            ]
+        },
         "Pagination": {
             "type": "object",
             "properties": {
+                "continue": {
+                    "description": "Continue is the cursor to fetch the next page with. It is empty on the\nlast page.",
+                    "type": "string"
+                },
                 "limit": {
                     "description": "Limit is the maximum number of items requested.",
                     "type": "integer"
@@ -206,10 +1129,129 @@ const docTemplate = `{
                     "type": "integer"
                 },
                 "total": {
-                    "description": "Total is the total number of items available.",
+                    "description": "Total is the total number of items available. When paginating with a\ncursor it is a lower bound if the remaining items cannot be counted.",
                     "type": "integer"
                 }
             }
+        },
+        "PatchCanvasRequest": {
+            "type": "object",
+            "properties": {
+                "displayName": {
+                    "type": "string",
+                    "minLength": 1
+                }
+            }
+        },
+        "Quantity": {
+            "type": "object",
+            "properties": {
+                "Format": {
+                    "type": "string",
+                    "enum": [
+                        "DecimalExponent",
+                        "BinarySI",
+                        "DecimalSI"
+                    ],
+                    "x-enum-comments": {
+                        "BinarySI": "e.g., 12Mi (12 * 2^20)",
+                        "DecimalExponent": "e.g., 12e6",
+                        "DecimalSI": "e.g., 12M  (12 * 10^6)"
+                    },
+                    "x-enum-descriptions": [
+                        "e.g., 12e6",
+                        "e.g., 12Mi (12 * 2^20)",
+                        "e.g., 12M  (12 * 10^6)"
+                    ],
+                    "x-enum-varnames": [
+                        "DecimalExponent",
+                        "BinarySI",
+                        "DecimalSI"
+                    ]
+                }
+            }
+        },
+        "ResourceList": {
+            "type": "object",
+            "additionalProperties": {
+                "$ref": "#/definitions/Quantity"
+            }
+        },
+        "UpdateCanvasRequest": {
+            "type": "object",
+            "required": [
+                "displayName"
+            ],
+            "properties": {
+                "displayName": {
+                    "type": "string"
+                }
+            }
+        },
+        "ValidationErrorResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "Code is a machine-readable error code.",
+                    "type": "string"
+                },
+                "details": {
+                    "description": "Details lists the fields that failed validation.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/FieldError"
+                    }
+                },
+                "message": {
+                    "description": "Message is a human-readable description of the error.",
+                    "type": "string"
+                },
+                "requestId": {
+                    "description": "RequestID is a unique identifier for the request, useful for debugging.",
+                    "type": "string"
+                }
+            }
+        },
+        "Version": {
+            "type": "object",
+            "properties": {
+                "buildDate": {
+                    "description": "BuildDate is the date/time on which the application was built.",
+                    "type": "string"
+                },
+                "compiler": {
+                    "description": "Compiler indicates what Go compiler was used for the build.",
+                    "type": "string"
+                },
+                "gitCommit": {
+                    "description": "GitCommit is the ID (sha) of the last commit to the application's source\ncode that is included in this build.",
+                    "type": "string"
+                },
+                "gitTreeDirty": {
+                    "description": "GitTreeDirty is true if the application's source code contained\nuncommitted changes at the time it was built; otherwise it is false.",
+                    "type": "boolean"
+                },
+                "goVersion": {
+                    "description": "GoVersion is the version of Go that was used to build the application.",
+                    "type": "string"
+                },
+                "platform": {
+                    "description": "Platform indicates the OS and CPU architecture for which the application\nwas built.",
+                    "type": "string"
+                },
+                "version": {
+                    "description": "Version is a human-friendly version string.",
+                    "type": "string"
+                }
+            }
+        }
+    },
+    "securityDefinitions": {
+        "BearerAuth": {
+            "description": "Bearer token, formatted as \"Bearer \u003ctoken\u003e\".",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
         }
     }
 }`
@@ -1,6 +1,8 @@
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -24,16 +26,36 @@ type Canvas struct {
 }
 
 const (
-	// AnnotationCanvas is the annotation key for an orray canvas.
+	// AnnotationCanvas is the annotation key for an orray canvas. Its value
+	// is the name of the canvas a namespace belongs to.
 	AnnotationCanvas = "orray.dev/canvas"
 	// AnnotationManagedBy is the annotation key for the manager of the canvas.
 	AnnotationManagedBy = "orray.dev/managed-by"
+	// AnnotationAdopted marks the namespaces that existed before their canvas
+	// and were adopted by it.
+	AnnotationAdopted = "orray.dev/adopted"
+	// AnnotationDeletionProtection, set to "true" on a Canvas, rejects its
+	// deletion while pods are running in the namespaces it owns.
+	AnnotationDeletionProtection = "orray.dev/deletion-protection"
 
 	// FinalizerCanvas is the finalizer for a Canvas.
 	FinalizerCanvas = "orray.dev/finalizer"
 
 	// ConditionTypeReady is the condition type for the Canvas's ready state.
 	ConditionTypeReady = "Ready"
+	// ConditionTypeNamespaceReady is the condition type for the state of the
+	// Canvas's namespace, explaining why an existing namespace could not be
+	// adopted.
+	ConditionTypeNamespaceReady = "NamespaceReady"
+	// ConditionTypeTerminating is the condition type for the progress of the
+	// deletion of the Canvas.
+	ConditionTypeTerminating = "Terminating"
+	// ConditionTypeRBACReady is the condition type for the state of the Roles
+	// and RoleBindings granting the Canvas's members access to its namespace.
+	ConditionTypeRBACReady = "RBACReady"
+	// ConditionTypeNetworkIsolated is the condition type for whether the
+	// ingress traffic of the Canvas's namespace is restricted.
+	ConditionTypeNetworkIsolated = "NetworkIsolated"
 
 	// ReasonProvisioning is the reason for the Canvas being in a provisioning state.
 	ReasonProvisioning = "Provisioning"
@@ -41,6 +63,25 @@ const (
 	ReasonProvisioned = "Provisioned"
 	// ReasonFailed is the reason for the Canvas being in a failed state.
 	ReasonFailed = "Failed"
+	// ReasonSynced is the reason for a Canvas's resources matching its spec.
+	ReasonSynced = "Synced"
+	// ReasonAdopted is the reason for a Canvas using a namespace that existed
+	// before it.
+	ReasonAdopted = "Adopted"
+	// ReasonNamespaceConflict is the reason for a Canvas's namespace existing
+	// but not being adoptable.
+	ReasonNamespaceConflict = "NamespaceConflict"
+	// ReasonNamespaceNotOwned is the reason for a Canvas's members not being
+	// granted access to a namespace the Canvas did not create.
+	ReasonNamespaceNotOwned = "NamespaceNotOwned"
+	// ReasonDeletionBlocked is the reason for the deletion of a Canvas
+	// waiting for its pods to stop.
+	ReasonDeletionBlocked = "DeletionBlocked"
+	// ReasonCleaningUp is the reason for a Canvas being deleted.
+	ReasonCleaningUp = "CleaningUp"
+	// ReasonIsolationDisabled is the reason for a Canvas's namespace
+	// accepting traffic from anywhere.
+	ReasonIsolationDisabled = "IsolationDisabled"
 
 	// ManagedByValue is the value for the ManagedBy annotation.
 	ManagedByValue = "orray-controller"
@@ -50,9 +91,230 @@ func (p *Canvas) GetStatus() *CanvasStatus {
 	return &p.Status
 }
 
+// HomeNamespace returns the name of the namespace holding the objects
+// managed for the Canvas.
+func (p *Canvas) HomeNamespace() string {
+	if p.Spec.HomeNamespace != "" {
+		return p.Spec.HomeNamespace
+	}
+	return p.Name
+}
+
 // Spec describes the Canvas.
 type CanvasSpec struct {
 	DisplayName string `json:"displayName,omitempty"`
+
+	// AdoptionPolicy selects what happens when the namespace of the Canvas
+	// already exists.
+	//
+	// +kubebuilder:default=Refuse
+	// +optional
+	AdoptionPolicy AdoptionPolicy `json:"adoptionPolicy,omitempty"`
+
+	// HomeNamespace is the namespace provisioned for the Canvas, which holds
+	// the objects managed for it. It defaults to the name of the Canvas and
+	// cannot be changed. An existing namespace may only be used if it is
+	// annotated with orray.dev/canvas set to the name of the Canvas.
+	//
+	// +optional
+	HomeNamespace string `json:"homeNamespace,omitempty"`
+
+	// DeletionPolicy selects what happens to the namespaces of the Canvas
+	// when it is deleted.
+	//
+	// +kubebuilder:default=Delete
+	// +optional
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+
+	// Namespaces are the existing namespaces the Canvas spans besides its
+	// home namespace. They are tracked, not provisioned.
+	//
+	// +optional
+	// +listType=atomic
+	Namespaces []CanvasNamespace `json:"namespaces,omitempty"`
+
+	// Members are granted access to the Canvas's namespace according to
+	// their role.
+	//
+	// +optional
+	// +listType=atomic
+	Members []CanvasMember `json:"members,omitempty"`
+
+	// Resources limits the resources the workloads of the Canvas may use.
+	//
+	// +optional
+	Resources *CanvasResources `json:"resources,omitempty"`
+
+	// Network restricts the traffic the workloads of the Canvas accept.
+	//
+	// +optional
+	Network *CanvasNetwork `json:"network,omitempty"`
+}
+
+// CanvasNamespace selects namespaces a Canvas spans, either by name or by
+// labels.
+//
+// +kubebuilder:validation:XValidation:rule="has(self.name) != has(self.selector)",message="exactly one of name or selector must be set"
+type CanvasNamespace struct {
+	// Name is the name of a namespace.
+	//
+	// +optional
+	Name string `json:"name,omitempty"`
+	// Selector selects the namespaces whose labels match.
+	//
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+}
+
+// NetworkIsolation selects which ingress traffic the namespace of a Canvas
+// accepts.
+//
+// +kubebuilder:validation:Enum=Open;NamespaceIsolated;DenyAll
+type NetworkIsolation string
+
+const (
+	// NetworkIsolationOpen accepts traffic from anywhere.
+	NetworkIsolationOpen NetworkIsolation = "Open"
+	// NetworkIsolationNamespaceIsolated accepts traffic from the namespace of
+	// the Canvas and from the allowed peers.
+	NetworkIsolationNamespaceIsolated NetworkIsolation = "NamespaceIsolated"
+	// NetworkIsolationDenyAll accepts traffic from the allowed peers only,
+	// not even from the namespace of the Canvas.
+	NetworkIsolationDenyAll NetworkIsolation = "DenyAll"
+)
+
+// CanvasNetwork restricts the ingress traffic of the namespace of a Canvas
+// through NetworkPolicies, which the network plugin of the cluster must
+// enforce.
+type CanvasNetwork struct {
+	// Isolation selects which traffic is accepted.
+	//
+	// +kubebuilder:default=Open
+	// +optional
+	Isolation NetworkIsolation `json:"isolation,omitempty"`
+	// AllowedCanvases are the Canvases whose workloads may reach the
+	// workloads of this Canvas when it is isolated. Canvases that do not
+	// exist are ignored until they are created.
+	//
+	// +optional
+	// +listType=set
+	AllowedCanvases []string `json:"allowedCanvases,omitempty"`
+	// AllowedNamespaces are the namespaces, such as the one of an ingress
+	// controller, whose workloads may reach the workloads of this Canvas
+	// when it is isolated.
+	//
+	// +optional
+	// +listType=set
+	AllowedNamespaces []string `json:"allowedNamespaces,omitempty"`
+}
+
+// CanvasResources limits the resources of the namespace of a Canvas through a
+// ResourceQuota and a LimitRange.
+type CanvasResources struct {
+	// Quota is the total amount of each resource the namespace may use, such
+	// as "requests.cpu", "limits.memory" or "pods".
+	//
+	// +optional
+	Quota corev1.ResourceList `json:"quota,omitempty"`
+	// DefaultRequests are the resource requests of the containers that do not
+	// set theirs.
+	//
+	// +optional
+	DefaultRequests corev1.ResourceList `json:"defaultRequests,omitempty"`
+	// DefaultLimits are the resource limits of the containers that do not set
+	// theirs.
+	//
+	// +optional
+	DefaultLimits corev1.ResourceList `json:"defaultLimits,omitempty"`
+}
+
+// AdoptionPolicy selects whether a Canvas takes over a namespace that existed
+// before it. Namespaces controlled by another object, and the namespaces of
+// the system and of Orray, are never adopted.
+//
+// +kubebuilder:validation:Enum=Refuse;Adopt;AdoptAndOwn
+type AdoptionPolicy string
+
+const (
+	// AdoptionPolicyRefuse fails the provisioning of the Canvas if its
+	// namespace already exists.
+	AdoptionPolicyRefuse AdoptionPolicy = "Refuse"
+	// AdoptionPolicyAdopt uses the existing namespace, which is kept when the
+	// Canvas is deleted.
+	AdoptionPolicyAdopt AdoptionPolicy = "Adopt"
+	// AdoptionPolicyAdoptAndOwn uses the existing namespace and makes the
+	// Canvas its owner, so that it is deleted along with the Canvas.
+	AdoptionPolicyAdoptAndOwn AdoptionPolicy = "AdoptAndOwn"
+)
+
+// DeletionPolicy selects what happens to the namespace of a Canvas and its
+// workloads when the Canvas is deleted. The objects managed for the Canvas
+// are deleted in any case. A Canvas keeping its namespace cannot be deleted
+// in the foreground, which would delete the namespace it owns first.
+//
+// +kubebuilder:validation:Enum=Delete;Orphan;Retain
+type DeletionPolicy string
+
+const (
+	// DeletionPolicyDelete deletes the namespace of the Canvas if the Canvas
+	// owns it, along with its workloads.
+	DeletionPolicyDelete DeletionPolicy = "Delete"
+	// DeletionPolicyOrphan keeps the namespace of the Canvas and its
+	// workloads.
+	DeletionPolicyOrphan DeletionPolicy = "Orphan"
+	// DeletionPolicyRetain keeps the namespace of the Canvas and its
+	// workloads, and saves the Canvas in a ConfigMap of the namespace so that
+	// it can be recreated.
+	DeletionPolicyRetain DeletionPolicy = "Retain"
+)
+
+// CanvasRole is the access a member has to the namespace of a Canvas.
+//
+// +kubebuilder:validation:Enum=viewer;editor;owner
+type CanvasRole string
+
+const (
+	// CanvasRoleViewer can read the workloads of the Canvas, but not its
+	// Secrets.
+	CanvasRoleViewer CanvasRole = "viewer"
+	// CanvasRoleEditor can read and change the workloads and Secrets of the
+	// Canvas.
+	CanvasRoleEditor CanvasRole = "editor"
+	// CanvasRoleOwner has full control over the namespace of the Canvas,
+	// including who has access to it.
+	CanvasRoleOwner CanvasRole = "owner"
+)
+
+// CanvasRoles lists the roles in increasing order of access.
+var CanvasRoles = []CanvasRole{CanvasRoleViewer, CanvasRoleEditor, CanvasRoleOwner}
+
+const (
+	// MemberKindUser is the kind of members that are users.
+	MemberKindUser = "User"
+	// MemberKindGroup is the kind of members that are groups of users.
+	MemberKindGroup = "Group"
+	// MemberKindServiceAccount is the kind of members that are service
+	// accounts.
+	MemberKindServiceAccount = "ServiceAccount"
+)
+
+// CanvasMember grants a role on a Canvas to a user, group or service account.
+type CanvasMember struct {
+	// Kind is the kind of the member, one of User, Group or ServiceAccount.
+	//
+	// +kubebuilder:validation:Enum=User;Group;ServiceAccount
+	Kind string `json:"kind"`
+	// Name is the name of the member.
+	//
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+	// Namespace is the namespace of a ServiceAccount member. It defaults to
+	// the namespace of the Canvas.
+	//
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+	// Role is the access granted to the member.
+	Role CanvasRole `json:"role"`
 }
 
 // Status describes the current status of a Canvas.
@@ -68,6 +330,52 @@ type CanvasStatus struct {
 	// ObservedGeneration represents the .metadata.generation that this
 	// instance was reconciled against.
 	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Quota reports how much of its quota the Canvas's namespace uses.
+	//
+	// +optional
+	Quota *CanvasQuotaStatus `json:"quota,omitempty"`
+	// Namespaces are the sorted names of the existing namespaces the Canvas
+	// spans, including its home namespace.
+	//
+	// +optional
+	// +listType=set
+	Namespaces []string `json:"namespaces,omitempty"`
+}
+
+// CanvasQuotaStatus reports the usage of a Canvas's namespace against its
+// quota.
+type CanvasQuotaStatus struct {
+	// Hard is the enforced quota of each resource.
+	Hard corev1.ResourceList `json:"hard,omitempty"`
+	// Used is the current usage of each resource.
+	Used corev1.ResourceList `json:"used,omitempty"`
+}
+
+// CanvasPhase is a high-level summary of where a Canvas is in its lifecycle.
+type CanvasPhase string
+
+const (
+	// CanvasPhaseProvisioning means the Canvas is still being set up.
+	CanvasPhaseProvisioning CanvasPhase = "Provisioning"
+	// CanvasPhaseReady means the Canvas has been provisioned successfully.
+	CanvasPhaseReady CanvasPhase = "Ready"
+	// CanvasPhaseFailed means the last provisioning attempt failed.
+	CanvasPhaseFailed CanvasPhase = "Failed"
+)
+
+// Phase derives the phase of the Canvas from its Ready condition.
+func (p *CanvasStatus) Phase() CanvasPhase {
+	ready := meta.FindStatusCondition(p.Conditions, ConditionTypeReady)
+	switch {
+	case ready == nil:
+		return CanvasPhaseProvisioning
+	case ready.Status == metav1.ConditionTrue:
+		return CanvasPhaseReady
+	case ready.Reason == ReasonFailed:
+		return CanvasPhaseFailed
+	default:
+		return CanvasPhaseProvisioning
+	}
 }
 
 // GetConditions implements the conditions.Getter interface.
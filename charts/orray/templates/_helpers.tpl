@@ -97,3 +97,30 @@ Prioritizes limits over requests, with a fallback to limits if neither is set.
     limits.cpu
   {{- end -}}
 {{- end -}}
+
+{{/*
+OpenTelemetry tracing settings shared by all components
+*/}}
+{{- define "orray.tracing.config" -}}
+TRACING_ENABLED: {{ quote .Values.global.tracing.enabled }}
+{{- if .Values.global.tracing.enabled }}
+OTEL_EXPORTER_OTLP_ENDPOINT: {{ quote .Values.global.tracing.endpoint }}
+OTEL_EXPORTER_OTLP_PROTOCOL: {{ quote .Values.global.tracing.protocol }}
+OTEL_TRACES_SAMPLER: {{ quote .Values.global.tracing.sampler }}
+OTEL_TRACES_SAMPLER_ARG: {{ quote .Values.global.tracing.samplerArg }}
+{{- with .Values.global.tracing.resourceAttributes }}
+OTEL_RESOURCE_ATTRIBUTES: {{ include "orray.tracing.resourceAttributes" . | quote }}
+{{- end }}
+{{- end }}
+{{- end }}
+
+{{/*
+Resource attributes formatted as OTEL_RESOURCE_ATTRIBUTES expects them
+*/}}
+{{- define "orray.tracing.resourceAttributes" -}}
+{{- $attributes := list }}
+{{- range $key, $value := . }}
+{{- $attributes = append $attributes (printf "%s=%s" $key (toString $value)) }}
+{{- end }}
+{{- join "," $attributes }}
+{{- end }}
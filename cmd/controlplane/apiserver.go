@@ -46,11 +46,19 @@ func newAPIServerCommand() *cobra.Command {
 }
 
 func (s *apiServer) run(ctx context.Context) error {
+	flushTraces, err := s.setupTracing(ctx, "orray-apiserver")
+	if err != nil {
+		return fmt.Errorf("error setting up tracing: %w", err)
+	}
+	defer flushTraces()
+
 	restCfg, err := kubernetes.NewInClusterConfig()
 	if err != nil {
 		return fmt.Errorf("error loading in-cluster REST config: %w", err)
 	}
 
+	rest.InstrumentKubernetesConfig(restCfg)
+
 	scheme := runtime.NewScheme()
 	if err = v1alpha1.AddToScheme(scheme); err != nil {
 		return fmt.Errorf(
@@ -59,7 +67,7 @@ func (s *apiServer) run(ctx context.Context) error {
 		)
 	}
 
-	kubeClient, err := client.New(restCfg, client.Options{
+	kubeClient, err := client.NewWithWatch(restCfg, client.Options{
 		Scheme: scheme,
 	})
 	if err != nil {
@@ -75,7 +83,10 @@ func (s *apiServer) run(ctx context.Context) error {
 	if err := rest.NewConfig(cfg, *s.Config); err != nil {
 		return err
 	}
-	server := rest.NewServer(ctx, cfg, s.Logger, kubeClient, clientset)
+	server, err := rest.NewServer(ctx, cfg, s.Logger, kubeClient, clientset)
+	if err != nil {
+		return err
+	}
 
 	return server.Run(ctx.Done())
 }
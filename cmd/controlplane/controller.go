@@ -9,6 +9,8 @@ import (
 	batchv1 "k8s.io/api/batch/v1"
 	coordinationv1 "k8s.io/api/coordination/v1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
 	ctrlruntime "sigs.k8s.io/controller-runtime"
 
 	"github.com/orray-proj/orray/api/v1alpha1"
@@ -56,6 +58,12 @@ func newControllerCommand() *cobra.Command {
 
 // run runs the controller
 func (c *controller) run(ctx context.Context) error {
+	flushTraces, err := c.setupTracing(ctx, "orray-controller")
+	if err != nil {
+		return fmt.Errorf("error setting up tracing: %w", err)
+	}
+	defer flushTraces()
+
 	mgr, err := c.setupControllerManager(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to setup orray controller manager: %w", err)
@@ -63,8 +71,10 @@ func (c *controller) run(ctx context.Context) error {
 
 	// Register Canvas Reconciler
 	if err = (&canvas.Reconciler{
-		Client: mgr.GetClient(),
-		Logger: c.Logger,
+		Client:    mgr.GetClient(),
+		Logger:    c.Logger,
+		Recorder:  mgr.GetEventRecorder("orray-controller"),
+		APIReader: mgr.GetAPIReader(),
 	}).SetupWithManager(mgr); err != nil {
 		return fmt.Errorf("failed to setup canvas reconciler: %w", err)
 	}
@@ -103,6 +113,20 @@ func (c *controller) setupControllerManager(_ context.Context) (manager.Manager,
 		)
 	}
 
+	if err = networkingv1.AddToScheme(scheme); err != nil {
+		return nil, fmt.Errorf(
+			"error adding Kubernetes networking API to controller manager scheme: %w",
+			err,
+		)
+	}
+
+	if err = rbacv1.AddToScheme(scheme); err != nil {
+		return nil, fmt.Errorf(
+			"error adding Kubernetes RBAC API to controller manager scheme: %w",
+			err,
+		)
+	}
+
 	if err = v1alpha1.AddToScheme(scheme); err != nil {
 		return nil, fmt.Errorf(
 			"error adding orray v1alpha1 API to controller manager scheme: %w",
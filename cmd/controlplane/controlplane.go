@@ -1,10 +1,17 @@
 package main
 
 import (
+	"context"
+	"time"
+
 	"github.com/orray-proj/orray/pkg/logging"
 	internalServer "github.com/orray-proj/orray/pkg/server"
+	"github.com/orray-proj/orray/pkg/tracing"
 )
 
+// tracingShutdownTimeout bounds the time spent flushing spans on exit.
+const tracingShutdownTimeout = 5 * time.Second
+
 type baseComponent struct {
 	Logger *logging.Logger
 
@@ -36,3 +43,19 @@ func (b *baseComponent) bootstrap() error {
 	b.Logger = logger
 	return nil
 }
+
+// setupTracing configures the tracing of the component. The returned function
+// flushes the pending spans and is meant to be deferred.
+func (b *baseComponent) setupTracing(ctx context.Context, serviceName string) (func(), error) {
+	shutdown, err := tracing.Setup(ctx, b.Tracing, serviceName)
+	if err != nil {
+		return nil, err
+	}
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), tracingShutdownTimeout)
+		defer cancel()
+		if err := shutdown(ctx); err != nil {
+			b.Logger.Error(err, "Failed to flush traces")
+		}
+	}, nil
+}
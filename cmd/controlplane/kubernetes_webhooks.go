@@ -15,6 +15,7 @@ import (
 
 	"github.com/orray-proj/orray/api/v1alpha1"
 	"github.com/orray-proj/orray/pkg/kubernetes"
+	"github.com/orray-proj/orray/pkg/tracing"
 	versionpkg "github.com/orray-proj/orray/pkg/version"
 	"github.com/orray-proj/orray/pkg/webhook/canvas"
 	"github.com/spf13/cobra"
@@ -53,6 +54,12 @@ func newKubernetesWebhooksServerCommand() *cobra.Command {
 
 // run starts the webhooks server
 func (k *kubernetesWebhooksServer) run(ctx context.Context) error {
+	flushTraces, err := k.setupTracing(ctx, "orray-kubernetes-webhooks-server")
+	if err != nil {
+		return fmt.Errorf("error setting up tracing: %w", err)
+	}
+	defer flushTraces()
+
 	restCfg, err := kubernetes.NewInClusterConfig()
 	if err != nil {
 		return fmt.Errorf("error loading in-cluster REST config: %w", err)
@@ -77,9 +84,9 @@ func (k *kubernetesWebhooksServer) run(ctx context.Context) error {
 
 	mgr, err := ctrlruntime.NewManager(restCfg, ctrlruntime.Options{
 		Scheme: scheme,
-		WebhookServer: webhook.NewServer(webhook.Options{
+		WebhookServer: tracing.NewWebhookServer(webhook.NewServer(webhook.Options{
 			Port: 9443,
-		}),
+		})),
 		Metrics: metricsserver.Options{
 			BindAddress: k.MetricsBindAddress,
 		},
@@ -99,7 +106,8 @@ func (k *kubernetesWebhooksServer) run(ctx context.Context) error {
 	}
 
 	// Register Canvas Webhook
-	if err := canvas.NewCanvasWebhook(k.Logger).SetupWebhookWithManager(mgr); err != nil {
+	canvasWebhook := canvas.NewCanvasWebhook(k.Logger, mgr.GetAPIReader(), k.OrrayNamespace)
+	if err := canvasWebhook.SetupWebhookWithManager(mgr); err != nil {
 		return fmt.Errorf("failed to setup canvas webhook: %w", err)
 	}
 
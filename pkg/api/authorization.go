@@ -0,0 +1,293 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	orrayv1alpha1 "github.com/orray-proj/orray/api/v1alpha1"
+	"github.com/orray-proj/orray/pkg/auth"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// maxCachedDecisions bounds the number of authorization decisions an
+// authorizingCanvasService remembers.
+const maxCachedDecisions = 4096
+
+// canvasResource identifies Canvas resources in authorization checks and errors.
+var canvasResource = schema.GroupResource{
+	Group:    orrayv1alpha1.GroupVersion.Group,
+	Resource: "canvases",
+}
+
+// decisionKey identifies an authorization decision.
+type decisionKey struct {
+	user string
+	verb string
+	name string
+}
+
+type cachedDecision struct {
+	allowed bool
+	expires time.Time
+}
+
+type authorizingCanvasService struct {
+	next       CanvasService
+	authorizer auth.Authorizer
+	ttl        time.Duration
+	now        func() time.Time
+
+	mu        sync.Mutex
+	decisions map[decisionKey]cachedDecision
+}
+
+// NewAuthorizingCanvasService wraps next so that every call is authorized
+// for the user carried by the context. Calls from unauthenticated contexts
+// are rejected.
+//
+// Users allowed to list or watch canvases cluster-wide see every canvas.
+// Other users only see the canvases they are allowed to get.
+//
+// Decisions are remembered for ttl for each user and canvas, so that listing
+// and watching canvases does not check every canvas again. A zero ttl
+// disables the cache.
+func NewAuthorizingCanvasService(next CanvasService, authorizer auth.Authorizer, ttl time.Duration) CanvasService {
+	return &authorizingCanvasService{
+		next:       next,
+		authorizer: authorizer,
+		ttl:        ttl,
+		now:        time.Now,
+		decisions:  make(map[decisionKey]cachedDecision),
+	}
+}
+
+// allowed reports whether the user carried by ctx may perform verb on the
+// named canvas, or on all canvases when name is empty.
+func (s *authorizingCanvasService) allowed(ctx context.Context, verb, name string) (bool, error) {
+	user, ok := auth.UserFrom(ctx)
+	if !ok {
+		return false, apierrors.NewUnauthorized("authentication required")
+	}
+
+	// The groups and extra attributes of the user are part of the key, since
+	// they take part in the decision.
+	userKey, err := json.Marshal(user)
+	if err != nil {
+		return false, err
+	}
+	key := decisionKey{user: string(userKey), verb: verb, name: name}
+	if allowed, ok := s.cachedDecision(key); ok {
+		return allowed, nil
+	}
+
+	allowed, _, err := s.authorizer.Authorize(ctx, user, authorizationv1.ResourceAttributes{
+		Verb:     verb,
+		Group:    canvasResource.Group,
+		Version:  orrayv1alpha1.GroupVersion.Version,
+		Resource: canvasResource.Resource,
+		Name:     name,
+	})
+	if err != nil {
+		return false, err
+	}
+	s.cacheDecision(key, allowed)
+	return allowed, nil
+}
+
+// cachedDecision returns the decision remembered for key, if it has not
+// expired yet.
+func (s *authorizingCanvasService) cachedDecision(key decisionKey) (bool, bool) {
+	if s.ttl <= 0 {
+		return false, false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	decision, ok := s.decisions[key]
+	if !ok || !s.now().Before(decision.expires) {
+		return false, false
+	}
+	return decision.allowed, true
+}
+
+// cacheDecision remembers the decision for key. Expired decisions are
+// dropped when the cache is full.
+func (s *authorizingCanvasService) cacheDecision(key decisionKey, allowed bool) {
+	if s.ttl <= 0 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	if len(s.decisions) >= maxCachedDecisions {
+		for k, decision := range s.decisions {
+			if !now.Before(decision.expires) {
+				delete(s.decisions, k)
+			}
+		}
+	}
+	if len(s.decisions) < maxCachedDecisions {
+		s.decisions[key] = cachedDecision{allowed: allowed, expires: now.Add(s.ttl)}
+	}
+}
+
+// authorize returns a Forbidden error unless the user carried by ctx may
+// perform verb on the named canvas.
+func (s *authorizingCanvasService) authorize(ctx context.Context, verb, name string) error {
+	allowed, err := s.allowed(ctx, verb, name)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		user, _ := auth.UserFrom(ctx)
+		return apierrors.NewForbidden(canvasResource, name,
+			fmt.Errorf("user %q cannot %s canvases", user.Username, verb))
+	}
+	return nil
+}
+
+// Create implements CanvasService.
+func (s *authorizingCanvasService) Create(ctx context.Context, name, displayName string) (*orrayv1alpha1.Canvas, error) {
+	if err := s.authorize(ctx, "create", ""); err != nil {
+		return nil, err
+	}
+	return s.next.Create(ctx, name, displayName)
+}
+
+// List implements CanvasService. The items of a page are filtered, so pages
+// may hold fewer items than requested and the remaining item count is dropped.
+func (s *authorizingCanvasService) List(
+	ctx context.Context, opts CanvasListOptions,
+) (*orrayv1alpha1.CanvasList, error) {
+	listAll, err := s.allowed(ctx, "list", "")
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := s.next.List(ctx, opts)
+	if err != nil || listAll {
+		return list, err
+	}
+
+	items := list.Items[:0]
+	for _, canvas := range list.Items {
+		allowed, err := s.allowed(ctx, "get", canvas.Name)
+		if err != nil {
+			return nil, err
+		}
+		if allowed {
+			items = append(items, canvas)
+		}
+	}
+	list.Items = items
+	list.RemainingItemCount = nil
+	return list, nil
+}
+
+// Get implements CanvasService.
+func (s *authorizingCanvasService) Get(ctx context.Context, name string) (*orrayv1alpha1.Canvas, error) {
+	if err := s.authorize(ctx, "get", name); err != nil {
+		return nil, err
+	}
+	return s.next.Get(ctx, name)
+}
+
+// Update implements CanvasService.
+func (s *authorizingCanvasService) Update(
+	ctx context.Context, name, displayName string, preconditions Preconditions,
+) (*orrayv1alpha1.Canvas, error) {
+	if err := s.authorize(ctx, "update", name); err != nil {
+		return nil, err
+	}
+	return s.next.Update(ctx, name, displayName, preconditions)
+}
+
+// Patch implements CanvasService.
+func (s *authorizingCanvasService) Patch(
+	ctx context.Context, name string, displayName *string, preconditions Preconditions,
+) (*orrayv1alpha1.Canvas, error) {
+	if err := s.authorize(ctx, "patch", name); err != nil {
+		return nil, err
+	}
+	return s.next.Patch(ctx, name, displayName, preconditions)
+}
+
+// Delete implements CanvasService.
+func (s *authorizingCanvasService) Delete(ctx context.Context, name string, preconditions Preconditions) error {
+	if err := s.authorize(ctx, "delete", name); err != nil {
+		return err
+	}
+	return s.next.Delete(ctx, name, preconditions)
+}
+
+// Watch implements CanvasService. Users who cannot watch canvases
+// cluster-wide only receive events for the canvases they are allowed to get.
+func (s *authorizingCanvasService) Watch(ctx context.Context, resourceVersion string) (watch.Interface, error) {
+	watchAll, err := s.allowed(ctx, "watch", "")
+	if err != nil {
+		return nil, err
+	}
+
+	w, err := s.next.Watch(ctx, resourceVersion)
+	if err != nil || watchAll {
+		return w, err
+	}
+
+	return s.filterWatch(ctx, w), nil
+}
+
+// filterWatch forwards the events of w for the canvases the user carried by
+// ctx may get. The returned watch ends with an error event if a canvas cannot
+// be authorized, rather than silently dropping its events.
+func (s *authorizingCanvasService) filterWatch(ctx context.Context, w watch.Interface) watch.Interface {
+	result := make(chan watch.Event)
+	proxy := watch.NewProxyWatcher(result)
+	go func() {
+		defer close(result)
+		defer w.Stop()
+
+		send := func(event watch.Event) bool {
+			select {
+			case result <- event:
+				return true
+			case <-proxy.StopChan():
+				return false
+			}
+		}
+		for {
+			var event watch.Event
+			select {
+			case <-proxy.StopChan():
+				return
+			case e, ok := <-w.ResultChan():
+				if !ok {
+					return
+				}
+				event = e
+			}
+
+			canvas, ok := event.Object.(*orrayv1alpha1.Canvas)
+			if !ok || event.Type == watch.Bookmark {
+				if !send(event) {
+					return
+				}
+				continue
+			}
+			allowed, err := s.allowed(ctx, "get", canvas.Name)
+			if err != nil {
+				status := apierrors.NewInternalError(fmt.Errorf("failed to authorize canvas %s: %w", canvas.Name, err))
+				send(watch.Event{Type: watch.Error, Object: &status.ErrStatus})
+				return
+			}
+			if allowed && !send(event) {
+				return
+			}
+		}
+	}()
+	return proxy
+}
@@ -0,0 +1,237 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	orrayv1alpha1 "github.com/orray-proj/orray/api/v1alpha1"
+	"github.com/orray-proj/orray/pkg/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// staticAuthorizer allows the verbs listed for each user, keyed by canvas
+// name, with an empty name meaning all canvases.
+type staticAuthorizer map[string]map[string][]string
+
+func (a staticAuthorizer) Authorize(
+	_ context.Context, user *authenticationv1.UserInfo, attributes authorizationv1.ResourceAttributes,
+) (bool, string, error) {
+	for _, name := range []string{"", attributes.Name} {
+		for _, verb := range a[user.Username][name] {
+			if verb == attributes.Verb {
+				return true, "", nil
+			}
+		}
+	}
+	return false, "denied", nil
+}
+
+// countingAuthorizer counts the decisions of the wrapped authorizer, and fails
+// those about the canvas named failing.
+type countingAuthorizer struct {
+	auth.Authorizer
+	calls   int
+	failing string
+}
+
+func (a *countingAuthorizer) Authorize(
+	ctx context.Context, user *authenticationv1.UserInfo, attributes authorizationv1.ResourceAttributes,
+) (bool, string, error) {
+	a.calls++
+	if attributes.Name != "" && attributes.Name == a.failing {
+		return false, "", errors.New("authorizer unavailable")
+	}
+	return a.Authorizer.Authorize(ctx, user, attributes)
+}
+
+func TestAuthorizingCanvasService(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = orrayv1alpha1.AddToScheme(scheme)
+
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&orrayv1alpha1.Canvas{ObjectMeta: metav1.ObjectMeta{Name: "alpha"}},
+		&orrayv1alpha1.Canvas{ObjectMeta: metav1.ObjectMeta{Name: "bravo"}},
+	).Build()
+	service := NewAuthorizingCanvasService(NewCanvasService(fakeClient), staticAuthorizer{
+		"admin": {"": {"create", "get", "list", "watch", "update", "patch", "delete"}},
+		"alice": {"alpha": {"get", "update"}},
+	}, 0)
+
+	asUser := func(username string) context.Context {
+		return auth.WithUser(context.Background(), &authenticationv1.UserInfo{Username: username})
+	}
+	names := func(list *orrayv1alpha1.CanvasList) []string {
+		result := make([]string, 0, len(list.Items))
+		for _, canvas := range list.Items {
+			result = append(result, canvas.Name)
+		}
+		return result
+	}
+
+	t.Run("Unauthenticated", func(t *testing.T) {
+		_, err := service.Get(context.Background(), "alpha")
+
+		assert.True(t, apierrors.IsUnauthorized(err))
+	})
+
+	t.Run("List All Canvases", func(t *testing.T) {
+		list, err := service.List(asUser("admin"), CanvasListOptions{})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"alpha", "bravo"}, names(list))
+	})
+
+	t.Run("List Accessible Canvases", func(t *testing.T) {
+		list, err := service.List(asUser("alice"), CanvasListOptions{})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"alpha"}, names(list))
+
+		list, err = service.List(asUser("bob"), CanvasListOptions{})
+
+		assert.NoError(t, err)
+		assert.Empty(t, list.Items)
+	})
+
+	t.Run("Get", func(t *testing.T) {
+		canvas, err := service.Get(asUser("alice"), "alpha")
+		assert.NoError(t, err)
+		assert.Equal(t, "alpha", canvas.Name)
+
+		_, err = service.Get(asUser("alice"), "bravo")
+		assert.True(t, apierrors.IsForbidden(err))
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		_, err := service.Update(asUser("alice"), "alpha", "Alpha", Preconditions{})
+		assert.NoError(t, err)
+
+		_, err = service.Patch(asUser("alice"), "alpha", nil, Preconditions{})
+		assert.True(t, apierrors.IsForbidden(err))
+	})
+
+	t.Run("Create And Delete", func(t *testing.T) {
+		_, err := service.Create(asUser("alice"), "charlie", "Charlie")
+		assert.True(t, apierrors.IsForbidden(err))
+
+		_, err = service.Create(asUser("admin"), "charlie", "Charlie")
+		assert.NoError(t, err)
+
+		err = service.Delete(asUser("alice"), "charlie", Preconditions{})
+		assert.True(t, apierrors.IsForbidden(err))
+
+		err = service.Delete(asUser("admin"), "charlie", Preconditions{})
+		assert.NoError(t, err)
+	})
+
+	t.Run("Watch Accessible Canvases", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(asUser("alice"), 10*time.Second)
+		defer cancel()
+
+		w, err := service.Watch(ctx, "")
+		require.NoError(t, err)
+		defer w.Stop()
+
+		for _, name := range []string{"bravo", "alpha"} {
+			canvas, err := service.Get(asUser("admin"), name)
+			require.NoError(t, err)
+			canvas.Spec.DisplayName = "Updated"
+			require.NoError(t, fakeClient.Update(ctx, canvas))
+		}
+
+		select {
+		case event := <-w.ResultChan():
+			assert.Equal(t, watch.Modified, event.Type)
+			assert.Equal(t, "alpha", event.Object.(*orrayv1alpha1.Canvas).Name)
+		case <-ctx.Done():
+			t.Fatal("timed out waiting for watch event")
+		}
+	})
+}
+
+func TestAuthorizingCanvasServiceCache(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = orrayv1alpha1.AddToScheme(scheme)
+
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&orrayv1alpha1.Canvas{ObjectMeta: metav1.ObjectMeta{Name: "alpha"}},
+		&orrayv1alpha1.Canvas{ObjectMeta: metav1.ObjectMeta{Name: "bravo"}},
+	).Build()
+	authorizer := &countingAuthorizer{Authorizer: staticAuthorizer{
+		"admin": {"": {"get", "update"}},
+		"alice": {"alpha": {"get"}},
+	}, failing: "bravo"}
+	service := NewAuthorizingCanvasService(NewCanvasService(fakeClient), authorizer, time.Minute)
+	cached := service.(*authorizingCanvasService)
+	now := time.Now()
+	cached.now = func() time.Time { return now }
+
+	alice := auth.WithUser(context.Background(), &authenticationv1.UserInfo{Username: "alice"})
+
+	t.Run("DecisionsAreCached", func(t *testing.T) {
+		for range 2 {
+			_, err := service.Get(alice, "alpha")
+			require.NoError(t, err)
+		}
+		assert.Equal(t, 1, authorizer.calls)
+
+		// Other users are authorized on their own.
+		admin := auth.WithUser(context.Background(), &authenticationv1.UserInfo{Username: "admin"})
+		_, err := service.Get(admin, "alpha")
+		require.NoError(t, err)
+		assert.Equal(t, 2, authorizer.calls)
+
+		now = now.Add(2 * time.Minute)
+		_, err = service.Get(alice, "alpha")
+		require.NoError(t, err)
+		assert.Equal(t, 3, authorizer.calls)
+	})
+
+	t.Run("FailuresAreNotCached", func(t *testing.T) {
+		calls := authorizer.calls
+		for range 2 {
+			_, err := service.Get(alice, "bravo")
+			assert.Error(t, err)
+		}
+		assert.Equal(t, calls+2, authorizer.calls)
+	})
+
+	t.Run("WatchEndsWhenAuthorizationFails", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(alice, 10*time.Second)
+		defer cancel()
+
+		w, err := service.Watch(ctx, "")
+		require.NoError(t, err)
+		defer w.Stop()
+
+		canvas := &orrayv1alpha1.Canvas{}
+		require.NoError(t, fakeClient.Get(ctx, client.ObjectKey{Name: "bravo"}, canvas))
+		canvas.Spec.DisplayName = "Updated"
+		require.NoError(t, fakeClient.Update(ctx, canvas))
+
+		select {
+		case event := <-w.ResultChan():
+			require.Equal(t, watch.Error, event.Type)
+			assert.True(t, apierrors.IsInternalError(apierrors.FromObject(event.Object)))
+		case <-ctx.Done():
+			t.Fatal("timed out waiting for watch event")
+		}
+		select {
+		case _, ok := <-w.ResultChan():
+			assert.False(t, ok)
+		case <-ctx.Done():
+			t.Fatal("timed out waiting for the watch to end")
+		}
+	})
+}
@@ -1,27 +1,149 @@
 package api
 
 import (
+	"cmp"
 	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
 
 	orrayv1alpha1 "github.com/orray-proj/orray/api/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/watch"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 // CanvasService provides methods to interact with Canvas resources.
 type CanvasService interface {
 	Create(ctx context.Context, name, displayName string) (*orrayv1alpha1.Canvas, error)
-	List(ctx context.Context) (*orrayv1alpha1.CanvasList, error)
+	List(ctx context.Context, opts CanvasListOptions) (*orrayv1alpha1.CanvasList, error)
 	Get(ctx context.Context, name string) (*orrayv1alpha1.Canvas, error)
-	Delete(ctx context.Context, name string) error
+	Update(ctx context.Context, name, displayName string, preconditions Preconditions) (*orrayv1alpha1.Canvas, error)
+	Patch(
+		ctx context.Context, name string, displayName *string, preconditions Preconditions,
+	) (*orrayv1alpha1.Canvas, error)
+	Delete(ctx context.Context, name string, preconditions Preconditions) error
+	Watch(ctx context.Context, resourceVersion string) (watch.Interface, error)
+}
+
+// ErrPreconditionFailed is returned when a canvas does not satisfy the
+// Preconditions of a change.
+var ErrPreconditionFailed = errors.New("precondition failed")
+
+// Preconditions must hold for a change to a canvas to be applied.
+type Preconditions struct {
+	// MustExist requires the canvas to exist, so that a missing canvas fails
+	// the precondition rather than being reported as not found.
+	MustExist bool
+	// ResourceVersions, when set, must hold the current resource version of
+	// the canvas, so that changes based on a stale copy are rejected. It
+	// implies MustExist.
+	ResourceVersions []string
+}
+
+// mustExist reports whether p requires the canvas to exist.
+func (p Preconditions) mustExist() bool {
+	return p.MustExist || len(p.ResourceVersions) > 0
+}
+
+// check returns ErrPreconditionFailed if canvas does not satisfy p.
+func (p Preconditions) check(canvas *orrayv1alpha1.Canvas) error {
+	if len(p.ResourceVersions) > 0 && !slices.Contains(p.ResourceVersions, canvas.ResourceVersion) {
+		return fmt.Errorf("%w: canvas %q is at resource version %q, not one of %q",
+			ErrPreconditionFailed, canvas.Name, canvas.ResourceVersion, p.ResourceVersions)
+	}
+	return nil
+}
+
+// preconditionError reports a conflict with a concurrent change as a failed
+// precondition when the caller asked for a resource version, and a missing
+// canvas when the caller required it to exist.
+func (p Preconditions) preconditionError(err error) error {
+	if (len(p.ResourceVersions) > 0 && apierrors.IsConflict(err)) || (p.mustExist() && apierrors.IsNotFound(err)) {
+		return fmt.Errorf("%w: %w", ErrPreconditionFailed, err)
+	}
+	return err
+}
+
+// CanvasSort is the order in which canvases are listed.
+type CanvasSort string
+
+// Supported canvas sort orders. A leading dash reverses the order.
+const (
+	CanvasSortName            CanvasSort = "name"
+	CanvasSortNameDesc        CanvasSort = "-name"
+	CanvasSortCreatedAt       CanvasSort = "createdAt"
+	CanvasSortCreatedAtDesc   CanvasSort = "-createdAt"
+	CanvasSortDisplayName     CanvasSort = "displayName"
+	CanvasSortDisplayNameDesc CanvasSort = "-displayName"
+)
+
+// CanvasListOptions are the options for listing Canvas resources.
+//
+// LabelSelector and the embedded ListOptions are evaluated by the Kubernetes
+// API, while Query, Phase and Sort are applied to the returned items. Callers
+// that set any of the latter should not set a limit, so that they are applied
+// to the whole collection rather than to a single page.
+type CanvasListOptions struct {
+	ListOptions
+
+	// LabelSelector restricts the list to canvases whose labels match.
+	LabelSelector labels.Selector
+	// Query is matched case-insensitively against the name and display name.
+	Query string
+	// Phase restricts the list to canvases in the given phase.
+	Phase orrayv1alpha1.CanvasPhase
+	// Sort orders the list. The API server order is kept when empty.
+	Sort CanvasSort
+}
+
+// FiltersItems reports whether the options filter or sort the listed items
+// after they are returned by the Kubernetes API.
+func (o CanvasListOptions) FiltersItems() bool {
+	return o.Query != "" || o.Phase != "" || o.Sort != ""
+}
+
+// matches reports whether the canvas passes the Query and Phase filters.
+func (o CanvasListOptions) matches(canvas *orrayv1alpha1.Canvas) bool {
+	if o.Phase != "" && canvas.Status.Phase() != o.Phase {
+		return false
+	}
+	if o.Query != "" {
+		query := strings.ToLower(o.Query)
+		return strings.Contains(strings.ToLower(canvas.Name), query) ||
+			strings.Contains(strings.ToLower(canvas.Spec.DisplayName), query)
+	}
+	return true
+}
+
+// compare orders two canvases according to Sort, falling back to their names.
+func (o CanvasListOptions) compare(a, b orrayv1alpha1.Canvas) int {
+	byName := strings.Compare(a.Name, b.Name)
+	switch o.Sort {
+	case CanvasSortNameDesc:
+		return -byName
+	case CanvasSortCreatedAt:
+		return cmp.Or(a.CreationTimestamp.Compare(b.CreationTimestamp.Time), byName)
+	case CanvasSortCreatedAtDesc:
+		return cmp.Or(b.CreationTimestamp.Compare(a.CreationTimestamp.Time), byName)
+	case CanvasSortDisplayName:
+		return cmp.Or(strings.Compare(strings.ToLower(a.Spec.DisplayName), strings.ToLower(b.Spec.DisplayName)), byName)
+	case CanvasSortDisplayNameDesc:
+		return cmp.Or(strings.Compare(strings.ToLower(b.Spec.DisplayName), strings.ToLower(a.Spec.DisplayName)), byName)
+	default:
+		return byName
+	}
 }
 
 type canvasService struct {
-	kubeClient client.Client
+	kubeClient client.WithWatch
 }
 
 // NewCanvasService creates a new CanvasService.
-func NewCanvasService(kubeClient client.Client) CanvasService {
+func NewCanvasService(kubeClient client.WithWatch) CanvasService {
 	return &canvasService{
 		kubeClient: kubeClient,
 	}
@@ -45,12 +167,24 @@ func (s *canvasService) Create(ctx context.Context, name, displayName string) (*
 	return canvas, nil
 }
 
-// List lists all Canvas resources.
-func (s *canvasService) List(ctx context.Context) (*orrayv1alpha1.CanvasList, error) {
+// List lists Canvas resources matching opts.
+func (s *canvasService) List(ctx context.Context, opts CanvasListOptions) (*orrayv1alpha1.CanvasList, error) {
+	listOpts := opts.clientOptions()
+	if opts.LabelSelector != nil {
+		listOpts = append(listOpts, client.MatchingLabelsSelector{Selector: opts.LabelSelector})
+	}
+
 	list := &orrayv1alpha1.CanvasList{}
-	if err := s.kubeClient.List(ctx, list); err != nil {
+	if err := s.kubeClient.List(ctx, list, listOpts...); err != nil {
 		return nil, err
 	}
+
+	if opts.FiltersItems() {
+		list.Items = slices.DeleteFunc(list.Items, func(canvas orrayv1alpha1.Canvas) bool {
+			return !opts.matches(&canvas)
+		})
+		slices.SortStableFunc(list.Items, opts.compare)
+	}
 	return list, nil
 }
 
@@ -63,12 +197,85 @@ func (s *canvasService) Get(ctx context.Context, name string) (*orrayv1alpha1.Ca
 	return canvas, nil
 }
 
+// Update replaces the spec of an existing Canvas resource.
+func (s *canvasService) Update(
+	ctx context.Context, name, displayName string, preconditions Preconditions,
+) (*orrayv1alpha1.Canvas, error) {
+	canvas, err := s.Get(ctx, name)
+	if err != nil {
+		return nil, preconditions.preconditionError(err)
+	}
+	if err := preconditions.check(canvas); err != nil {
+		return nil, err
+	}
+
+	canvas.Spec.DisplayName = displayName
+	if err := s.kubeClient.Update(ctx, canvas); err != nil {
+		return nil, preconditions.preconditionError(err)
+	}
+	return canvas, nil
+}
+
+// Patch applies a merge patch to an existing Canvas resource. Nil fields are
+// left untouched.
+func (s *canvasService) Patch(
+	ctx context.Context, name string, displayName *string, preconditions Preconditions,
+) (*orrayv1alpha1.Canvas, error) {
+	canvas, err := s.Get(ctx, name)
+	if err != nil {
+		return nil, preconditions.preconditionError(err)
+	}
+	if err := preconditions.check(canvas); err != nil {
+		return nil, err
+	}
+
+	var opts []client.MergeFromOption
+	if len(preconditions.ResourceVersions) > 0 {
+		// Sending the resource version makes the API server reject the patch
+		// if the canvas changed since it was read.
+		opts = append(opts, client.MergeFromWithOptimisticLock{})
+	}
+	patch := client.MergeFromWithOptions(canvas.DeepCopy(), opts...)
+	if displayName != nil {
+		canvas.Spec.DisplayName = *displayName
+	}
+	if err := s.kubeClient.Patch(ctx, canvas, patch); err != nil {
+		return nil, preconditions.preconditionError(err)
+	}
+	return canvas, nil
+}
+
 // Delete deletes a Canvas resource by name.
-func (s *canvasService) Delete(ctx context.Context, name string) error {
+func (s *canvasService) Delete(ctx context.Context, name string, preconditions Preconditions) error {
 	canvas := &orrayv1alpha1.Canvas{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: name,
 		},
 	}
-	return s.kubeClient.Delete(ctx, canvas)
+
+	var opts []client.DeleteOption
+	if preconditions.mustExist() {
+		current, err := s.Get(ctx, name)
+		if err != nil {
+			return preconditions.preconditionError(err)
+		}
+		if err := preconditions.check(current); err != nil {
+			return err
+		}
+		if len(preconditions.ResourceVersions) > 0 {
+			opts = append(opts, client.Preconditions{ResourceVersion: &current.ResourceVersion})
+		}
+	}
+	return preconditions.preconditionError(s.kubeClient.Delete(ctx, canvas, opts...))
+}
+
+// Watch watches Canvas resources for changes. If resourceVersion is set, the
+// watch resumes from that version instead of starting from the current state.
+func (s *canvasService) Watch(ctx context.Context, resourceVersion string) (watch.Interface, error) {
+	return s.kubeClient.Watch(ctx, &orrayv1alpha1.CanvasList{}, &client.ListOptions{
+		Raw: &metav1.ListOptions{
+			ResourceVersion:     resourceVersion,
+			AllowWatchBookmarks: true,
+		},
+	})
 }
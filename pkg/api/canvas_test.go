@@ -3,9 +3,13 @@ package api
 import (
 	"context"
 	"testing"
+	"time"
 
 	orrayv1alpha1 "github.com/orray-proj/orray/api/v1alpha1"
 	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
@@ -30,7 +34,7 @@ func TestCanvasService(t *testing.T) {
 	})
 
 	t.Run("List Canvases", func(t *testing.T) {
-		list, err := service.List(ctx)
+		list, err := service.List(ctx, CanvasListOptions{})
 
 		assert.NoError(t, err)
 		assert.NotNil(t, list)
@@ -39,7 +43,7 @@ func TestCanvasService(t *testing.T) {
 	})
 
 	t.Run("Get Canvas", func(t *testing.T) {
-		list, _ := service.List(ctx)
+		list, _ := service.List(ctx, CanvasListOptions{})
 		name := list.Items[0].Name
 
 		canvas, err := service.Get(ctx, name)
@@ -49,14 +53,175 @@ func TestCanvasService(t *testing.T) {
 		assert.Equal(t, name, canvas.Name)
 	})
 
+	t.Run("Update Canvas", func(t *testing.T) {
+		canvas, err := service.Update(ctx, "test", "Updated Canvas", Preconditions{})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Updated Canvas", canvas.Spec.DisplayName)
+
+		stored, _ := service.Get(ctx, "test")
+		assert.Equal(t, "Updated Canvas", stored.Spec.DisplayName)
+	})
+
+	t.Run("Update Missing Canvas", func(t *testing.T) {
+		_, err := service.Update(ctx, "missing", "Missing Canvas", Preconditions{})
+
+		assert.True(t, apierrors.IsNotFound(err))
+	})
+
+	t.Run("Patch Canvas", func(t *testing.T) {
+		canvas, err := service.Patch(ctx, "test", nil, Preconditions{})
+		assert.NoError(t, err)
+		assert.Equal(t, "Updated Canvas", canvas.Spec.DisplayName)
+
+		displayName := "Patched Canvas"
+		canvas, err = service.Patch(ctx, "test", &displayName, Preconditions{})
+		assert.NoError(t, err)
+		assert.Equal(t, displayName, canvas.Spec.DisplayName)
+
+		stored, _ := service.Get(ctx, "test")
+		assert.Equal(t, displayName, stored.Spec.DisplayName)
+	})
+
+	t.Run("Stale Resource Version", func(t *testing.T) {
+		stored, _ := service.Get(ctx, "test")
+		stale := Preconditions{ResourceVersions: []string{"1"}}
+		assert.NotEqual(t, "1", stored.ResourceVersion)
+
+		_, err := service.Update(ctx, "test", "Stale Canvas", stale)
+		assert.ErrorIs(t, err, ErrPreconditionFailed)
+		displayName := "Stale Canvas"
+		_, err = service.Patch(ctx, "test", &displayName, stale)
+		assert.ErrorIs(t, err, ErrPreconditionFailed)
+		err = service.Delete(ctx, "test", stale)
+		assert.ErrorIs(t, err, ErrPreconditionFailed)
+
+		unchanged, _ := service.Get(ctx, "test")
+		assert.Equal(t, stored.ResourceVersion, unchanged.ResourceVersion)
+	})
+
+	t.Run("Current Resource Version", func(t *testing.T) {
+		stored, _ := service.Get(ctx, "test")
+		current := Preconditions{ResourceVersions: []string{"1", stored.ResourceVersion}}
+
+		canvas, err := service.Update(ctx, "test", "Current Canvas", current)
+		assert.NoError(t, err)
+		assert.NotEqual(t, stored.ResourceVersion, canvas.ResourceVersion)
+
+		displayName := "Patched Current Canvas"
+		canvas, err = service.Patch(ctx, "test", &displayName,
+			Preconditions{ResourceVersions: []string{canvas.ResourceVersion}})
+		assert.NoError(t, err)
+		assert.Equal(t, displayName, canvas.Spec.DisplayName)
+	})
+
 	t.Run("Delete Canvas", func(t *testing.T) {
-		list, _ := service.List(ctx)
+		list, _ := service.List(ctx, CanvasListOptions{})
 		name := list.Items[0].Name
 
-		err := service.Delete(ctx, name)
+		err := service.Delete(ctx, name, Preconditions{})
 		assert.NoError(t, err)
 
-		newList, _ := service.List(ctx)
+		newList, _ := service.List(ctx, CanvasListOptions{})
 		assert.Len(t, newList.Items, 0)
 	})
 }
+
+func TestCanvasServiceListOptions(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = orrayv1alpha1.AddToScheme(scheme)
+
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	newCanvas := func(name, displayName, team string, age time.Duration, ready bool) *orrayv1alpha1.Canvas {
+		status := metav1.ConditionFalse
+		if ready {
+			status = metav1.ConditionTrue
+		}
+		return &orrayv1alpha1.Canvas{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Labels:            map[string]string{"team": team},
+				CreationTimestamp: metav1.NewTime(base.Add(-age)),
+			},
+			Spec: orrayv1alpha1.CanvasSpec{DisplayName: displayName},
+			Status: orrayv1alpha1.CanvasStatus{
+				Conditions: []metav1.Condition{{
+					Type:   orrayv1alpha1.ConditionTypeReady,
+					Status: status,
+					Reason: orrayv1alpha1.ReasonProvisioned,
+				}},
+			},
+		}
+	}
+
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		newCanvas("alpha", "Payments", "payments", 3*time.Hour, true),
+		newCanvas("bravo", "Checkout", "payments", time.Hour, false),
+		newCanvas("charlie", "analytics", "data", 2*time.Hour, true),
+	).Build()
+	service := NewCanvasService(fakeClient)
+	ctx := context.Background()
+
+	names := func(list *orrayv1alpha1.CanvasList) []string {
+		result := make([]string, 0, len(list.Items))
+		for _, canvas := range list.Items {
+			result = append(result, canvas.Name)
+		}
+		return result
+	}
+
+	tests := []struct {
+		name     string
+		opts     CanvasListOptions
+		expected []string
+	}{
+		{
+			name:     "no options",
+			expected: []string{"alpha", "bravo", "charlie"},
+		},
+		{
+			name:     "label selector",
+			opts:     CanvasListOptions{LabelSelector: labels.SelectorFromSet(labels.Set{"team": "payments"})},
+			expected: []string{"alpha", "bravo"},
+		},
+		{
+			name:     "query matches name",
+			opts:     CanvasListOptions{Query: "CHAR"},
+			expected: []string{"charlie"},
+		},
+		{
+			name:     "query matches display name",
+			opts:     CanvasListOptions{Query: "check"},
+			expected: []string{"bravo"},
+		},
+		{
+			name:     "phase",
+			opts:     CanvasListOptions{Phase: orrayv1alpha1.CanvasPhaseReady},
+			expected: []string{"alpha", "charlie"},
+		},
+		{
+			name:     "sort by name descending",
+			opts:     CanvasListOptions{Sort: CanvasSortNameDesc},
+			expected: []string{"charlie", "bravo", "alpha"},
+		},
+		{
+			name:     "sort by newest first",
+			opts:     CanvasListOptions{Sort: CanvasSortCreatedAtDesc},
+			expected: []string{"bravo", "charlie", "alpha"},
+		},
+		{
+			name:     "sort by display name",
+			opts:     CanvasListOptions{Sort: CanvasSortDisplayName},
+			expected: []string{"charlie", "bravo", "alpha"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			list, err := service.List(ctx, tt.opts)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, names(list))
+		})
+	}
+}
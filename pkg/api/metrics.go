@@ -0,0 +1,72 @@
+package api
+
+import (
+	"context"
+
+	orrayv1alpha1 "github.com/orray-proj/orray/api/v1alpha1"
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var canvasOperationsTotal = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "orray",
+		Subsystem: "apiserver",
+		Name:      "canvas_operations_total",
+		Help:      "Number of canvas changes requested through the API, partitioned by operation and result.",
+	},
+	[]string{"operation", "result"},
+)
+
+func init() {
+	metrics.Registry.MustRegister(canvasOperationsTotal)
+}
+
+type instrumentedCanvasService struct {
+	CanvasService
+}
+
+// NewInstrumentedCanvasService wraps next so that the canvas changes it
+// performs are counted.
+func NewInstrumentedCanvasService(next CanvasService) CanvasService {
+	return &instrumentedCanvasService{CanvasService: next}
+}
+
+// record counts an operation and returns its error unchanged.
+func record(operation string, err error) error {
+	result := "success"
+	if err != nil {
+		result = "error"
+	}
+	canvasOperationsTotal.WithLabelValues(operation, result).Inc()
+	return err
+}
+
+// Create implements CanvasService.
+func (s *instrumentedCanvasService) Create(
+	ctx context.Context, name, displayName string,
+) (*orrayv1alpha1.Canvas, error) {
+	canvas, err := s.CanvasService.Create(ctx, name, displayName)
+	return canvas, record("create", err)
+}
+
+// Update implements CanvasService.
+func (s *instrumentedCanvasService) Update(
+	ctx context.Context, name, displayName string, preconditions Preconditions,
+) (*orrayv1alpha1.Canvas, error) {
+	canvas, err := s.CanvasService.Update(ctx, name, displayName, preconditions)
+	return canvas, record("update", err)
+}
+
+// Patch implements CanvasService.
+func (s *instrumentedCanvasService) Patch(
+	ctx context.Context, name string, displayName *string, preconditions Preconditions,
+) (*orrayv1alpha1.Canvas, error) {
+	canvas, err := s.CanvasService.Patch(ctx, name, displayName, preconditions)
+	return canvas, record("patch", err)
+}
+
+// Delete implements CanvasService.
+func (s *instrumentedCanvasService) Delete(ctx context.Context, name string, preconditions Preconditions) error {
+	return record("delete", s.CanvasService.Delete(ctx, name, preconditions))
+}
@@ -0,0 +1,36 @@
+package api
+
+import (
+	"context"
+	"testing"
+
+	orrayv1alpha1 "github.com/orray-proj/orray/api/v1alpha1"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestInstrumentedCanvasService(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = orrayv1alpha1.AddToScheme(scheme)
+
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).Build()
+	service := NewInstrumentedCanvasService(NewCanvasService(fakeClient))
+	ctx := context.Background()
+
+	count := func(operation, result string) float64 {
+		return testutil.ToFloat64(canvasOperationsTotal.WithLabelValues(operation, result))
+	}
+	created, failed, deleted := count("create", "success"), count("create", "error"), count("delete", "success")
+
+	_, err := service.Create(ctx, "test", "Test Canvas")
+	assert.NoError(t, err)
+	_, err = service.Create(ctx, "test", "Test Canvas")
+	assert.Error(t, err)
+	assert.NoError(t, service.Delete(ctx, "test", Preconditions{}))
+
+	assert.Equal(t, created+1, count("create", "success"))
+	assert.Equal(t, failed+1, count("create", "error"))
+	assert.Equal(t, deleted+1, count("delete", "success"))
+}
@@ -0,0 +1,23 @@
+package api
+
+import "sigs.k8s.io/controller-runtime/pkg/client"
+
+// ListOptions are the options shared by all list operations.
+type ListOptions struct {
+	// Limit is the maximum number of items to return. Zero means no limit.
+	Limit int64
+	// Continue is the token returned by a previous paginated list call.
+	Continue string
+}
+
+// clientOptions converts the options to controller-runtime list options.
+func (o ListOptions) clientOptions() []client.ListOption {
+	var opts []client.ListOption
+	if o.Limit > 0 {
+		opts = append(opts, client.Limit(o.Limit))
+	}
+	if o.Continue != "" {
+		opts = append(opts, client.Continue(o.Continue))
+	}
+	return opts
+}
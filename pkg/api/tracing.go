@@ -0,0 +1,113 @@
+package api
+
+import (
+	"context"
+
+	orrayv1alpha1 "github.com/orray-proj/orray/api/v1alpha1"
+	"github.com/orray-proj/orray/pkg/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+const canvasNameAttribute = attribute.Key("orray.canvas.name")
+
+// tracerName is the instrumentation scope of the canvas service spans.
+const tracerName = "github.com/orray-proj/orray/pkg/api"
+
+type tracingCanvasService struct {
+	next   CanvasService
+	tracer trace.Tracer
+}
+
+// NewTracingCanvasService wraps next so that every call runs in its own span,
+// a child of the span carried by the call's context. Spans are started with
+// the global tracer provider.
+func NewTracingCanvasService(next CanvasService) CanvasService {
+	return &tracingCanvasService{
+		next:   next,
+		tracer: otel.Tracer(tracerName),
+	}
+}
+
+// start starts the span of a call to method.
+func (s *tracingCanvasService) start(
+	ctx context.Context, method string, attributes ...attribute.KeyValue,
+) (context.Context, trace.Span) {
+	return s.tracer.Start(ctx, "CanvasService."+method, trace.WithAttributes(attributes...))
+}
+
+// end records err on span and ends it.
+func end(span trace.Span, err error) {
+	tracing.RecordError(span, err)
+	span.End()
+}
+
+// Create implements CanvasService.
+func (s *tracingCanvasService) Create(
+	ctx context.Context, name, displayName string,
+) (*orrayv1alpha1.Canvas, error) {
+	ctx, span := s.start(ctx, "Create", canvasNameAttribute.String(name))
+	canvas, err := s.next.Create(ctx, name, displayName)
+	end(span, err)
+	return canvas, err
+}
+
+// List implements CanvasService.
+func (s *tracingCanvasService) List(
+	ctx context.Context, opts CanvasListOptions,
+) (*orrayv1alpha1.CanvasList, error) {
+	ctx, span := s.start(ctx, "List")
+	list, err := s.next.List(ctx, opts)
+	if err == nil {
+		span.SetAttributes(attribute.Int("orray.canvas.count", len(list.Items)))
+	}
+	end(span, err)
+	return list, err
+}
+
+// Get implements CanvasService.
+func (s *tracingCanvasService) Get(ctx context.Context, name string) (*orrayv1alpha1.Canvas, error) {
+	ctx, span := s.start(ctx, "Get", canvasNameAttribute.String(name))
+	canvas, err := s.next.Get(ctx, name)
+	end(span, err)
+	return canvas, err
+}
+
+// Update implements CanvasService.
+func (s *tracingCanvasService) Update(
+	ctx context.Context, name, displayName string, preconditions Preconditions,
+) (*orrayv1alpha1.Canvas, error) {
+	ctx, span := s.start(ctx, "Update", canvasNameAttribute.String(name))
+	canvas, err := s.next.Update(ctx, name, displayName, preconditions)
+	end(span, err)
+	return canvas, err
+}
+
+// Patch implements CanvasService.
+func (s *tracingCanvasService) Patch(
+	ctx context.Context, name string, displayName *string, preconditions Preconditions,
+) (*orrayv1alpha1.Canvas, error) {
+	ctx, span := s.start(ctx, "Patch", canvasNameAttribute.String(name))
+	canvas, err := s.next.Patch(ctx, name, displayName, preconditions)
+	end(span, err)
+	return canvas, err
+}
+
+// Delete implements CanvasService.
+func (s *tracingCanvasService) Delete(ctx context.Context, name string, preconditions Preconditions) error {
+	ctx, span := s.start(ctx, "Delete", canvasNameAttribute.String(name))
+	err := s.next.Delete(ctx, name, preconditions)
+	end(span, err)
+	return err
+}
+
+// Watch implements CanvasService. The span only covers establishing the
+// watch, not the lifetime of the stream.
+func (s *tracingCanvasService) Watch(ctx context.Context, resourceVersion string) (watch.Interface, error) {
+	ctx, span := s.start(ctx, "Watch")
+	w, err := s.next.Watch(ctx, resourceVersion)
+	end(span, err)
+	return w, err
+}
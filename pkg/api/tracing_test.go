@@ -0,0 +1,55 @@
+package api
+
+import (
+	"context"
+	"testing"
+
+	orrayv1alpha1 "github.com/orray-proj/orray/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestTracingCanvasService(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = orrayv1alpha1.AddToScheme(scheme)
+
+	previous := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	otel.SetTracerProvider(provider)
+
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).Build()
+	service := NewTracingCanvasService(NewCanvasService(fakeClient))
+
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "request")
+	_, err := service.Create(ctx, "test", "Test Canvas")
+	assert.NoError(t, err)
+	_, err = service.Get(ctx, "missing")
+	assert.Error(t, err)
+	_, err = service.List(ctx, CanvasListOptions{})
+	assert.NoError(t, err)
+	parent.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 4)
+
+	create, get, list := spans[0], spans[1], spans[2]
+	assert.Equal(t, "CanvasService.Create", create.Name())
+	assert.Equal(t, parent.SpanContext().SpanID(), create.Parent().SpanID())
+	assert.Contains(t, create.Attributes(), canvasNameAttribute.String("test"))
+	assert.Equal(t, codes.Unset, create.Status().Code)
+
+	assert.Equal(t, "CanvasService.Get", get.Name())
+	assert.Equal(t, codes.Error, get.Status().Code)
+	assert.Len(t, get.Events(), 1)
+
+	assert.Equal(t, "CanvasService.List", list.Name())
+	assert.Equal(t, parent.SpanContext().TraceID(), list.SpanContext().TraceID())
+}
@@ -0,0 +1,90 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// ModeNone disables authentication.
+	ModeNone = "none"
+	// ModeStatic authenticates requests against a fixed set of tokens. It is
+	// meant for development only.
+	ModeStatic = "static"
+	// ModeTokenReview authenticates Kubernetes bearer tokens with the
+	// TokenReview API.
+	ModeTokenReview = "tokenreview"
+	// ModeOIDC authenticates OpenID Connect ID tokens.
+	ModeOIDC = "oidc"
+)
+
+// ErrUnauthenticated is returned when a token cannot be authenticated.
+var ErrUnauthenticated = errors.New("invalid bearer token")
+
+// Authenticator authenticates bearer tokens.
+type Authenticator interface {
+	// AuthenticateToken returns the user the token belongs to, or an error
+	// wrapping ErrUnauthenticated if the token is not valid.
+	AuthenticateToken(ctx context.Context, token string) (*authenticationv1.UserInfo, error)
+}
+
+// Config contains the options for authenticating and authorizing API clients.
+type Config struct {
+	// Mode selects the authenticator, one of none, static, tokenreview or oidc.
+	Mode string `env:"MODE" envDefault:"none"`
+	// StaticTokens lists the tokens accepted in static mode, each formatted
+	// as "token=username|group1|group2" and split on the last "=".
+	StaticTokens []string `env:"STATIC_TOKENS"`
+	// TokenReviewAudiences are the audiences tokens must be issued for in
+	// tokenreview mode. The API server's audiences are used when empty.
+	TokenReviewAudiences []string `env:"TOKEN_REVIEW_AUDIENCES"`
+	// TokenReviewCacheTTL is how long the users of reviewed tokens are
+	// remembered in tokenreview mode. Zero disables the cache.
+	TokenReviewCacheTTL time.Duration `env:"TOKEN_REVIEW_CACHE_TTL" envDefault:"10s"`
+	// OIDC configures the oidc mode.
+	OIDC OIDCConfig `envPrefix:"OIDC_"`
+
+	// AuthorizationMode selects the authorizer, one of none or
+	// subjectaccessreview.
+	AuthorizationMode string `env:"AUTHORIZATION_MODE" envDefault:"none"`
+	// AuthorizationCacheTTL is how long authorization decisions are
+	// remembered. Zero disables the cache.
+	AuthorizationCacheTTL time.Duration `env:"AUTHORIZATION_CACHE_TTL" envDefault:"10s"`
+}
+
+// NewAuthenticator creates the Authenticator selected by cfg. It returns nil
+// when authentication is disabled.
+func NewAuthenticator(ctx context.Context, cfg Config, clientset kubernetes.Interface) (Authenticator, error) {
+	switch cfg.Mode {
+	case ModeNone, "":
+		return nil, nil
+	case ModeStatic:
+		return NewStaticAuthenticator(cfg.StaticTokens)
+	case ModeTokenReview:
+		return NewCachingAuthenticator(
+			NewTokenReviewAuthenticator(clientset, cfg.TokenReviewAudiences), cfg.TokenReviewCacheTTL,
+		), nil
+	case ModeOIDC:
+		return NewOIDCAuthenticator(ctx, cfg.OIDC)
+	default:
+		return nil, fmt.Errorf("unknown authentication mode %q", cfg.Mode)
+	}
+}
+
+type userKey struct{}
+
+// WithUser returns a copy of ctx carrying the authenticated user.
+func WithUser(ctx context.Context, user *authenticationv1.UserInfo) context.Context {
+	return context.WithValue(ctx, userKey{}, user)
+}
+
+// UserFrom returns the authenticated user carried by ctx, if any.
+func UserFrom(ctx context.Context) (*authenticationv1.UserInfo, bool) {
+	user, ok := ctx.Value(userKey{}).(*authenticationv1.UserInfo)
+	return user, ok && user != nil
+}
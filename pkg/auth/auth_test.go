@@ -0,0 +1,203 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestStaticAuthenticator(t *testing.T) {
+	authenticator, err := NewStaticAuthenticator([]string{
+		"alice-token=alice|admins|devs",
+		"bob-token=bob",
+		"Y2Fyb2wtdG9rZW4==carol",
+	})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	t.Run("ValidToken", func(t *testing.T) {
+		user, err := authenticator.AuthenticateToken(ctx, "alice-token")
+
+		assert.NoError(t, err)
+		assert.Equal(t, &authenticationv1.UserInfo{
+			Username: "alice",
+			Groups:   []string{"admins", "devs"},
+		}, user)
+	})
+
+	t.Run("PaddedToken", func(t *testing.T) {
+		user, err := authenticator.AuthenticateToken(ctx, "Y2Fyb2wtdG9rZW4=")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "carol", user.Username)
+	})
+
+	t.Run("InvalidToken", func(t *testing.T) {
+		_, err := authenticator.AuthenticateToken(ctx, "unknown")
+
+		assert.ErrorIs(t, err, ErrUnauthenticated)
+	})
+
+	t.Run("InvalidEntry", func(t *testing.T) {
+		for _, entry := range []string{"missing-identity", "missing-username=", "=missing-token"} {
+			_, err := NewStaticAuthenticator([]string{entry})
+
+			assert.Error(t, err, entry)
+		}
+	})
+}
+
+func TestTokenReviewAuthenticator(t *testing.T) {
+	clientset := fake.NewClientset()
+	clientset.PrependReactor("create", "tokenreviews",
+		func(action k8stesting.Action) (bool, runtime.Object, error) {
+			review := action.(k8stesting.CreateAction).GetObject().(*authenticationv1.TokenReview)
+			assert.Equal(t, []string{"orray"}, review.Spec.Audiences)
+			if review.Spec.Token == "valid" {
+				review.Status = authenticationv1.TokenReviewStatus{
+					Authenticated: true,
+					User: authenticationv1.UserInfo{
+						Username: "system:serviceaccount:default:orray",
+						Groups:   []string{"system:serviceaccounts"},
+					},
+				}
+			} else {
+				review.Status = authenticationv1.TokenReviewStatus{Error: "token expired"}
+			}
+			return true, review, nil
+		})
+	authenticator := NewTokenReviewAuthenticator(clientset, []string{"orray"})
+	ctx := context.Background()
+
+	t.Run("ValidToken", func(t *testing.T) {
+		user, err := authenticator.AuthenticateToken(ctx, "valid")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "system:serviceaccount:default:orray", user.Username)
+		assert.Equal(t, []string{"system:serviceaccounts"}, user.Groups)
+	})
+
+	t.Run("InvalidToken", func(t *testing.T) {
+		_, err := authenticator.AuthenticateToken(ctx, "expired")
+
+		assert.ErrorIs(t, err, ErrUnauthenticated)
+		assert.ErrorContains(t, err, "token expired")
+	})
+}
+
+func TestCachingAuthenticator(t *testing.T) {
+	reviews := 0
+	clientset := fake.NewClientset()
+	clientset.PrependReactor("create", "tokenreviews",
+		func(action k8stesting.Action) (bool, runtime.Object, error) {
+			reviews++
+			review := action.(k8stesting.CreateAction).GetObject().(*authenticationv1.TokenReview)
+			if review.Spec.Token == "valid" {
+				review.Status = authenticationv1.TokenReviewStatus{
+					Authenticated: true,
+					User:          authenticationv1.UserInfo{Username: "alice"},
+				}
+			}
+			return true, review, nil
+		})
+	authenticator := NewCachingAuthenticator(NewTokenReviewAuthenticator(clientset, nil), time.Minute)
+	cache := authenticator.(*cachingAuthenticator)
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	for range 2 {
+		user, err := authenticator.AuthenticateToken(ctx, "valid")
+		require.NoError(t, err)
+		assert.Equal(t, "alice", user.Username)
+	}
+	assert.Equal(t, 1, reviews)
+
+	// Failures are not cached.
+	for range 2 {
+		_, err := authenticator.AuthenticateToken(ctx, "invalid")
+		assert.ErrorIs(t, err, ErrUnauthenticated)
+	}
+	assert.Equal(t, 3, reviews)
+
+	now = now.Add(2 * time.Minute)
+	_, err := authenticator.AuthenticateToken(ctx, "valid")
+	require.NoError(t, err)
+	assert.Equal(t, 4, reviews)
+}
+
+func TestNewAuthenticator(t *testing.T) {
+	ctx := context.Background()
+
+	authenticator, err := NewAuthenticator(ctx, Config{Mode: ModeNone}, nil)
+	assert.NoError(t, err)
+	assert.Nil(t, authenticator)
+
+	_, err = NewAuthenticator(ctx, Config{Mode: "basic"}, nil)
+	assert.Error(t, err)
+
+	_, err = NewAuthenticator(ctx, Config{Mode: ModeOIDC}, nil)
+	assert.Error(t, err)
+}
+
+func TestUserFrom(t *testing.T) {
+	_, ok := UserFrom(context.Background())
+	assert.False(t, ok)
+
+	user := &authenticationv1.UserInfo{Username: "alice"}
+	got, ok := UserFrom(WithUser(context.Background(), user))
+	assert.True(t, ok)
+	assert.Equal(t, user, got)
+}
+
+func TestSubjectAccessReviewAuthorizer(t *testing.T) {
+	clientset := fake.NewClientset()
+	clientset.PrependReactor("create", "subjectaccessreviews",
+		func(action k8stesting.Action) (bool, runtime.Object, error) {
+			review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SubjectAccessReview)
+			assert.Equal(t, []string{"admins"}, review.Spec.Groups)
+			assert.Equal(t, authorizationv1.ExtraValue{"a"}, review.Spec.Extra["scope"])
+			if review.Spec.User == "alice" && review.Spec.ResourceAttributes.Verb == "get" {
+				review.Status = authorizationv1.SubjectAccessReviewStatus{Allowed: true}
+			} else {
+				review.Status = authorizationv1.SubjectAccessReviewStatus{Reason: "no RBAC policy matched"}
+			}
+			return true, review, nil
+		})
+	authorizer := NewSubjectAccessReviewAuthorizer(clientset)
+	ctx := context.Background()
+	user := &authenticationv1.UserInfo{
+		Username: "alice",
+		Groups:   []string{"admins"},
+		Extra:    map[string]authenticationv1.ExtraValue{"scope": {"a"}},
+	}
+
+	allowed, _, err := authorizer.Authorize(ctx, user, authorizationv1.ResourceAttributes{Verb: "get"})
+	assert.NoError(t, err)
+	assert.True(t, allowed)
+
+	allowed, reason, err := authorizer.Authorize(ctx, user, authorizationv1.ResourceAttributes{Verb: "delete"})
+	assert.NoError(t, err)
+	assert.False(t, allowed)
+	assert.Equal(t, "no RBAC policy matched", reason)
+}
+
+func TestNewAuthorizer(t *testing.T) {
+	authorizer, err := NewAuthorizer(Config{AuthorizationMode: AuthorizationModeNone}, nil)
+	assert.NoError(t, err)
+	assert.Nil(t, authorizer)
+
+	_, err = NewAuthorizer(Config{Mode: ModeNone, AuthorizationMode: AuthorizationModeSubjectAccessReview}, nil)
+	assert.Error(t, err)
+
+	_, err = NewAuthorizer(Config{Mode: ModeStatic, AuthorizationMode: "rbac"}, nil)
+	assert.Error(t, err)
+}
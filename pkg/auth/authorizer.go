@@ -0,0 +1,79 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// AuthorizationModeNone performs every request with the API server's own
+	// permissions.
+	AuthorizationModeNone = "none"
+	// AuthorizationModeSubjectAccessReview checks the permissions of the
+	// authenticated user with the SubjectAccessReview API.
+	AuthorizationModeSubjectAccessReview = "subjectaccessreview"
+)
+
+// Authorizer decides whether a user may act on a Kubernetes resource.
+type Authorizer interface {
+	// Authorize reports whether user may perform the request described by
+	// attributes, along with the reason given for a denial.
+	Authorize(
+		ctx context.Context, user *authenticationv1.UserInfo, attributes authorizationv1.ResourceAttributes,
+	) (allowed bool, reason string, err error)
+}
+
+// NewAuthorizer creates the Authorizer selected by cfg. It returns nil when
+// authorization is disabled.
+func NewAuthorizer(cfg Config, clientset kubernetes.Interface) (Authorizer, error) {
+	switch cfg.AuthorizationMode {
+	case AuthorizationModeNone, "":
+		return nil, nil
+	case AuthorizationModeSubjectAccessReview:
+		if cfg.Mode == ModeNone || cfg.Mode == "" {
+			return nil, fmt.Errorf("authorization mode %q requires authentication", cfg.AuthorizationMode)
+		}
+		return NewSubjectAccessReviewAuthorizer(clientset), nil
+	default:
+		return nil, fmt.Errorf("unknown authorization mode %q", cfg.AuthorizationMode)
+	}
+}
+
+type subjectAccessReviewAuthorizer struct {
+	clientset kubernetes.Interface
+}
+
+// NewSubjectAccessReviewAuthorizer creates an Authorizer that delegates
+// decisions to the Kubernetes API server with SubjectAccessReviews.
+func NewSubjectAccessReviewAuthorizer(clientset kubernetes.Interface) Authorizer {
+	return &subjectAccessReviewAuthorizer{clientset: clientset}
+}
+
+// Authorize implements Authorizer.
+func (a *subjectAccessReviewAuthorizer) Authorize(
+	ctx context.Context, user *authenticationv1.UserInfo, attributes authorizationv1.ResourceAttributes,
+) (bool, string, error) {
+	extra := make(map[string]authorizationv1.ExtraValue, len(user.Extra))
+	for key, value := range user.Extra {
+		extra[key] = authorizationv1.ExtraValue(value)
+	}
+
+	review, err := a.clientset.AuthorizationV1().SubjectAccessReviews().Create(ctx, &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			ResourceAttributes: &attributes,
+			User:               user.Username,
+			Groups:             user.Groups,
+			UID:                user.UID,
+			Extra:              extra,
+		},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return false, "", fmt.Errorf("failed to review access: %w", err)
+	}
+	return review.Status.Allowed, review.Status.Reason, nil
+}
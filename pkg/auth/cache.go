@@ -0,0 +1,77 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"sync"
+	"time"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+)
+
+// maxCachedTokens bounds the number of tokens a cachingAuthenticator
+// remembers.
+const maxCachedTokens = 4096
+
+type cachedUser struct {
+	user    *authenticationv1.UserInfo
+	expires time.Time
+}
+
+type cachingAuthenticator struct {
+	delegate Authenticator
+	ttl      time.Duration
+	now      func() time.Time
+
+	mu    sync.Mutex
+	users map[[sha256.Size]byte]cachedUser
+}
+
+// NewCachingAuthenticator wraps delegate so that the users of successfully
+// authenticated tokens are remembered for ttl. Failures are never cached.
+func NewCachingAuthenticator(delegate Authenticator, ttl time.Duration) Authenticator {
+	if ttl <= 0 {
+		return delegate
+	}
+	return &cachingAuthenticator{
+		delegate: delegate,
+		ttl:      ttl,
+		now:      time.Now,
+		users:    make(map[[sha256.Size]byte]cachedUser),
+	}
+}
+
+// AuthenticateToken implements Authenticator.
+func (a *cachingAuthenticator) AuthenticateToken(
+	ctx context.Context, token string,
+) (*authenticationv1.UserInfo, error) {
+	// Tokens are only kept hashed.
+	key := sha256.Sum256([]byte(token))
+
+	a.mu.Lock()
+	cached, ok := a.users[key]
+	a.mu.Unlock()
+	if ok && a.now().Before(cached.expires) {
+		return cached.user, nil
+	}
+
+	user, err := a.delegate.AuthenticateToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	now := a.now()
+	if len(a.users) >= maxCachedTokens {
+		for k, entry := range a.users {
+			if !now.Before(entry.expires) {
+				delete(a.users, k)
+			}
+		}
+	}
+	if len(a.users) < maxCachedTokens {
+		a.users[key] = cachedUser{user: user, expires: now.Add(a.ttl)}
+	}
+	return user, nil
+}
@@ -0,0 +1,160 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/go-jose/go-jose/v4"
+	authenticationv1 "k8s.io/api/authentication/v1"
+)
+
+// OIDCConfig contains the options for verifying OpenID Connect ID tokens.
+type OIDCConfig struct {
+	// IssuerURL is the expected "iss" claim. Unless JWKSURL or JWKSFile is
+	// set, it is also used to discover the provider's signing keys.
+	IssuerURL string `env:"ISSUER_URL"`
+	// ClientID is the expected "aud" claim.
+	ClientID string `env:"CLIENT_ID"`
+	// JWKSURL overrides the JWKS endpoint found through discovery.
+	JWKSURL string `env:"JWKS_URL"`
+	// JWKSFile loads the signing keys from a local JWKS document instead of
+	// fetching them from the provider.
+	JWKSFile string `env:"JWKS_FILE"`
+	// SigningAlgorithms restricts the accepted signing algorithms.
+	SigningAlgorithms []string `env:"SIGNING_ALGORITHMS" envDefault:"RS256"`
+	// UsernameClaim is the claim holding the username.
+	UsernameClaim string `env:"USERNAME_CLAIM" envDefault:"sub"`
+	// UsernamePrefix is prepended to usernames to avoid clashes with other
+	// authentication methods. Like in the Kubernetes API server, it defaults
+	// to the issuer URL followed by "#" unless UsernameClaim is "email", and
+	// "-" disables it.
+	UsernamePrefix string `env:"USERNAME_PREFIX"`
+	// GroupsClaim is the claim holding the user's groups.
+	GroupsClaim string `env:"GROUPS_CLAIM" envDefault:"groups"`
+	// GroupsPrefix is prepended to every group.
+	GroupsPrefix string `env:"GROUPS_PREFIX"`
+}
+
+type oidcAuthenticator struct {
+	config   OIDCConfig
+	verifier *oidc.IDTokenVerifier
+}
+
+// NewOIDCAuthenticator creates an Authenticator that verifies OpenID Connect
+// ID tokens issued by cfg.IssuerURL.
+func NewOIDCAuthenticator(ctx context.Context, cfg OIDCConfig) (Authenticator, error) {
+	if cfg.IssuerURL == "" {
+		return nil, fmt.Errorf("oidc authentication requires an issuer URL")
+	}
+	if cfg.ClientID == "" {
+		return nil, fmt.Errorf("oidc authentication requires a client ID")
+	}
+
+	switch cfg.UsernamePrefix {
+	case "":
+		if cfg.UsernameClaim != "email" {
+			cfg.UsernamePrefix = cfg.IssuerURL + "#"
+		}
+	case "-":
+		cfg.UsernamePrefix = ""
+	}
+
+	verifierConfig := &oidc.Config{
+		ClientID:             cfg.ClientID,
+		SupportedSigningAlgs: cfg.SigningAlgorithms,
+	}
+
+	var verifier *oidc.IDTokenVerifier
+	switch {
+	case cfg.JWKSFile != "":
+		keySet, err := loadKeySet(cfg.JWKSFile)
+		if err != nil {
+			return nil, err
+		}
+		verifier = oidc.NewVerifier(cfg.IssuerURL, keySet, verifierConfig)
+	case cfg.JWKSURL != "":
+		// The remote key set keeps using ctx to fetch keys, so it must live as
+		// long as the authenticator.
+		keySet := oidc.NewRemoteKeySet(context.WithoutCancel(ctx), cfg.JWKSURL)
+		verifier = oidc.NewVerifier(cfg.IssuerURL, keySet, verifierConfig)
+	default:
+		provider, err := oidc.NewProvider(context.WithoutCancel(ctx), cfg.IssuerURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to discover oidc provider: %w", err)
+		}
+		verifier = provider.Verifier(verifierConfig)
+	}
+
+	return &oidcAuthenticator{
+		config:   cfg,
+		verifier: verifier,
+	}, nil
+}
+
+// loadKeySet reads the public keys of a JWKS document.
+func loadKeySet(path string) (*oidc.StaticKeySet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read jwks file: %w", err)
+	}
+
+	var jwks jose.JSONWebKeySet
+	if err := json.Unmarshal(data, &jwks); err != nil {
+		return nil, fmt.Errorf("failed to parse jwks file: %w", err)
+	}
+
+	keySet := &oidc.StaticKeySet{}
+	for _, key := range jwks.Keys {
+		if !key.IsPublic() {
+			key = key.Public()
+		}
+		if !key.Valid() {
+			return nil, fmt.Errorf("invalid key %q in jwks file", key.KeyID)
+		}
+		keySet.PublicKeys = append(keySet.PublicKeys, crypto.PublicKey(key.Key))
+	}
+	if len(keySet.PublicKeys) == 0 {
+		return nil, fmt.Errorf("jwks file %s contains no keys", path)
+	}
+	return keySet, nil
+}
+
+// AuthenticateToken implements Authenticator.
+func (a *oidcAuthenticator) AuthenticateToken(
+	ctx context.Context, token string,
+) (*authenticationv1.UserInfo, error) {
+	idToken, err := a.verifier.Verify(ctx, token)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUnauthenticated, err)
+	}
+
+	var claims map[string]any
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUnauthenticated, err)
+	}
+
+	username, ok := claims[a.config.UsernameClaim].(string)
+	if !ok || username == "" {
+		return nil, fmt.Errorf("%w: claim %q is missing", ErrUnauthenticated, a.config.UsernameClaim)
+	}
+
+	user := &authenticationv1.UserInfo{
+		Username: a.config.UsernamePrefix + username,
+		UID:      idToken.Subject,
+	}
+	switch groups := claims[a.config.GroupsClaim].(type) {
+	case string:
+		user.Groups = []string{a.config.GroupsPrefix + groups}
+	case []any:
+		for _, group := range groups {
+			if name, ok := group.(string); ok {
+				user.Groups = append(user.Groups, a.config.GroupsPrefix+name)
+			}
+		}
+	}
+	return user, nil
+}
@@ -0,0 +1,143 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOIDCAuthenticator(t *testing.T) {
+	const issuer = "https://issuer.example.com"
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	jwks, err := json.Marshal(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{{
+		Key:       key.Public(),
+		KeyID:     "test",
+		Algorithm: string(jose.RS256),
+		Use:       "sig",
+	}}})
+	require.NoError(t, err)
+	jwksFile := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(jwksFile, jwks, 0o600))
+
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.RS256, Key: key}, nil)
+	require.NoError(t, err)
+	sign := func(claims map[string]any) string {
+		payload, err := json.Marshal(claims)
+		require.NoError(t, err)
+		signed, err := signer.Sign(payload)
+		require.NoError(t, err)
+		token, err := signed.CompactSerialize()
+		require.NoError(t, err)
+		return token
+	}
+
+	authenticator, err := NewOIDCAuthenticator(context.Background(), OIDCConfig{
+		IssuerURL:         issuer,
+		ClientID:          "orray",
+		JWKSFile:          jwksFile,
+		SigningAlgorithms: []string{"RS256"},
+		UsernameClaim:     "email",
+		UsernamePrefix:    "oidc:",
+		GroupsClaim:       "groups",
+		GroupsPrefix:      "oidc:",
+	})
+	require.NoError(t, err)
+
+	claims := func(overrides map[string]any) map[string]any {
+		result := map[string]any{
+			"iss":    issuer,
+			"aud":    "orray",
+			"sub":    "1234",
+			"email":  "alice@example.com",
+			"groups": []string{"admins"},
+			"exp":    time.Now().Add(time.Hour).Unix(),
+		}
+		for k, v := range overrides {
+			result[k] = v
+		}
+		return result
+	}
+
+	t.Run("ValidToken", func(t *testing.T) {
+		user, err := authenticator.AuthenticateToken(context.Background(), sign(claims(nil)))
+
+		require.NoError(t, err)
+		assert.Equal(t, "oidc:alice@example.com", user.Username)
+		assert.Equal(t, "1234", user.UID)
+		assert.Equal(t, []string{"oidc:admins"}, user.Groups)
+	})
+
+	t.Run("UsernamePrefix", func(t *testing.T) {
+		tests := []struct {
+			claim    string
+			prefix   string
+			expected string
+		}{
+			{claim: "sub", expected: issuer + "#1234"},
+			{claim: "sub", prefix: "-", expected: "1234"},
+			{claim: "email", expected: "alice@example.com"},
+		}
+		for _, tt := range tests {
+			authenticator, err := NewOIDCAuthenticator(context.Background(), OIDCConfig{
+				IssuerURL:         issuer,
+				ClientID:          "orray",
+				JWKSFile:          jwksFile,
+				SigningAlgorithms: []string{"RS256"},
+				UsernameClaim:     tt.claim,
+				UsernamePrefix:    tt.prefix,
+			})
+			require.NoError(t, err)
+
+			user, err := authenticator.AuthenticateToken(context.Background(), sign(claims(nil)))
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, user.Username)
+		}
+	})
+
+	tests := []struct {
+		name   string
+		claims map[string]any
+	}{
+		{name: "WrongIssuer", claims: map[string]any{"iss": "https://other.example.com"}},
+		{name: "WrongAudience", claims: map[string]any{"aud": "other"}},
+		{name: "Expired", claims: map[string]any{"exp": time.Now().Add(-time.Hour).Unix()}},
+		{name: "MissingUsername", claims: map[string]any{"email": nil}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := authenticator.AuthenticateToken(context.Background(), sign(claims(tt.claims)))
+
+			assert.ErrorIs(t, err, ErrUnauthenticated)
+		})
+	}
+
+	t.Run("WrongKey", func(t *testing.T) {
+		otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+		require.NoError(t, err)
+		otherSigner, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.RS256, Key: otherKey}, nil)
+		require.NoError(t, err)
+		payload, err := json.Marshal(claims(nil))
+		require.NoError(t, err)
+		signed, err := otherSigner.Sign(payload)
+		require.NoError(t, err)
+		token, err := signed.CompactSerialize()
+		require.NoError(t, err)
+
+		_, err = authenticator.AuthenticateToken(context.Background(), token)
+
+		assert.ErrorIs(t, err, ErrUnauthenticated)
+	})
+}
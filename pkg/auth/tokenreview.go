@@ -0,0 +1,47 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+type tokenReviewAuthenticator struct {
+	clientset kubernetes.Interface
+	audiences []string
+}
+
+// NewTokenReviewAuthenticator creates an Authenticator that validates
+// Kubernetes bearer tokens with the TokenReview API.
+func NewTokenReviewAuthenticator(clientset kubernetes.Interface, audiences []string) Authenticator {
+	return &tokenReviewAuthenticator{
+		clientset: clientset,
+		audiences: audiences,
+	}
+}
+
+// AuthenticateToken implements Authenticator.
+func (a *tokenReviewAuthenticator) AuthenticateToken(
+	ctx context.Context, token string,
+) (*authenticationv1.UserInfo, error) {
+	review, err := a.clientset.AuthenticationV1().TokenReviews().Create(ctx, &authenticationv1.TokenReview{
+		Spec: authenticationv1.TokenReviewSpec{
+			Token:     token,
+			Audiences: a.audiences,
+		},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to review token: %w", err)
+	}
+
+	if !review.Status.Authenticated {
+		if review.Status.Error != "" {
+			return nil, fmt.Errorf("%w: %s", ErrUnauthenticated, review.Status.Error)
+		}
+		return nil, ErrUnauthenticated
+	}
+	return &review.Status.User, nil
+}
@@ -0,0 +1,245 @@
+package canvas
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/orray-proj/orray/api/v1alpha1"
+	"github.com/orray-proj/orray/pkg/kubernetes"
+	"github.com/orray-proj/orray/pkg/logging"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	// snapshotName is the name of the ConfigMap a retained canvas is saved
+	// in.
+	snapshotName = "orray-canvas-snapshot"
+	// snapshotKey is the key of the canvas in its snapshot.
+	snapshotKey = "canvas.json"
+
+	// protectedRequeueAfter is how often the pods of a protected canvas are
+	// checked while its deletion is blocked.
+	protectedRequeueAfter = 30 * time.Second
+)
+
+// deletionPolicy returns the deletion policy of canvas.
+func deletionPolicy(canvas *v1alpha1.Canvas) v1alpha1.DeletionPolicy {
+	if canvas.Spec.DeletionPolicy == "" {
+		return v1alpha1.DeletionPolicyDelete
+	}
+	return canvas.Spec.DeletionPolicy
+}
+
+func (r *Reconciler) reconcileDelete(ctx context.Context, canvas *v1alpha1.Canvas, log *logging.Logger) (ctrl.Result, error) {
+	if !controllerutil.ContainsFinalizer(canvas, v1alpha1.FinalizerCanvas) {
+		return ctrl.Result{}, nil
+	}
+
+	// Protected deletions are rejected by the webhook. This only catches the
+	// canvases deleted without the webhook, or whose pods started after the
+	// deletion was admitted.
+	if canvas.Annotations[v1alpha1.AnnotationDeletionProtection] == "true" {
+		namespace, err := r.namespaceWithRunningPods(ctx, canvas)
+		if err != nil {
+			log.Error(err, "Failed to list running pods")
+			return ctrl.Result{}, r.failTerminating(ctx, canvas, "Failed to list running pods", err, log)
+		}
+		if namespace != "" {
+			log.Info("Deletion is blocked by running pods", "namespace", namespace)
+			message := fmt.Sprintf("Deletion is blocked by %s while pods are running in namespace %s",
+				v1alpha1.AnnotationDeletionProtection, namespace)
+			r.Recorder.Eventf(canvas, nil, corev1.EventTypeWarning, eventReasonDeletionBlocked, actionDelete,
+				"%s", message)
+			err := r.setTerminating(ctx, canvas, v1alpha1.ReasonDeletionBlocked, message)
+			return ctrl.Result{RequeueAfter: protectedRequeueAfter}, err
+		}
+	}
+
+	if err := r.setTerminating(ctx, canvas, v1alpha1.ReasonCleaningUp,
+		fmt.Sprintf("Cleaning up with deletion policy %s", deletionPolicy(canvas))); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if err := r.deleteManagedObjects(ctx, canvas, log); err != nil {
+		log.Error(err, "Failed to delete managed objects")
+		return ctrl.Result{}, err
+	}
+
+	if deletionPolicy(canvas) == v1alpha1.DeletionPolicyRetain {
+		if err := r.saveSnapshot(ctx, canvas, log); err != nil {
+			log.Error(err, "Failed to save snapshot")
+			return ctrl.Result{}, r.failTerminating(ctx, canvas, "Failed to save snapshot", err, log)
+		}
+	}
+
+	// Owned namespaces are deleted by the garbage collector once the canvas
+	// is gone. The others are released.
+	if err := r.releaseNamespace(ctx, canvas, log); err != nil {
+		log.Error(err, "Failed to release namespace")
+		return ctrl.Result{}, r.failTerminating(ctx, canvas, "Failed to release namespace", err, log)
+	}
+
+	controllerutil.RemoveFinalizer(canvas, v1alpha1.FinalizerCanvas)
+	if err := r.Update(ctx, canvas); err != nil {
+		log.Error(err, "Failed to remove finalizer")
+		r.Recorder.Eventf(canvas, nil, corev1.EventTypeWarning, eventReasonFinalizerFailed, actionDelete,
+			"Failed to remove finalizer: %v", err)
+		return ctrl.Result{}, err
+	}
+	r.Recorder.Eventf(canvas, nil, corev1.EventTypeNormal, eventReasonFinalizerRemoved, actionDelete,
+		"Removed finalizer %s", v1alpha1.FinalizerCanvas)
+
+	return ctrl.Result{}, nil
+}
+
+// namespaceWithRunningPods returns the name of a namespace owned by canvas
+// in which pods are pending or running, or an empty string if there is none.
+// Pods are read from the API server, so that the controller does not cache
+// every pod of the cluster.
+func (r *Reconciler) namespaceWithRunningPods(ctx context.Context, canvas *v1alpha1.Canvas) (string, error) {
+	namespaces := append([]string{canvas.HomeNamespace()}, canvas.Status.Namespaces...)
+	slices.Sort(namespaces)
+	for _, name := range slices.Compact(namespaces) {
+		ns := &corev1.Namespace{}
+		if err := r.Get(ctx, client.ObjectKey{Name: name}, ns); err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return "", err
+		}
+		// The pods of the namespaces the canvas merely spans are not its
+		// own.
+		if !isControlledBy(ns, canvas) {
+			continue
+		}
+		active, err := kubernetes.HasActivePods(ctx, r.APIReader, name)
+		if err != nil {
+			return "", err
+		}
+		if active {
+			return name, nil
+		}
+	}
+	return "", nil
+}
+
+// deleteManagedObjects deletes the objects managed for canvas in its home
+// namespace. Access is revoked first, then the network isolation and the
+// quota are lifted.
+func (r *Reconciler) deleteManagedObjects(ctx context.Context, canvas *v1alpha1.Canvas, log *logging.Logger) error {
+	namespace := canvas.HomeNamespace()
+	var roleBindings []client.Object
+	for _, role := range v1alpha1.CanvasRoles {
+		roleBindings = append(roleBindings, &rbacv1.RoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: roleName(role), Namespace: namespace},
+		})
+	}
+
+	steps := []struct {
+		description string
+		objects     []client.Object
+	}{
+		{"role bindings", roleBindings},
+		{"network policy", []client.Object{&networkingv1.NetworkPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: networkPolicyName, Namespace: namespace},
+		}}},
+		{"limit range", []client.Object{&corev1.LimitRange{
+			ObjectMeta: metav1.ObjectMeta{Name: limitRangeName, Namespace: namespace},
+		}}},
+		{"resource quota", []client.Object{&corev1.ResourceQuota{
+			ObjectMeta: metav1.ObjectMeta{Name: resourceQuotaName, Namespace: namespace},
+		}}},
+	}
+	for _, step := range steps {
+		for _, obj := range step.objects {
+			deleted, err := r.deleteIfExists(ctx, obj)
+			if err != nil {
+				return r.failTerminating(ctx, canvas, "Failed to delete "+step.description, err, log)
+			}
+			if deleted {
+				log.Info("Deleted "+step.description, "name", obj.GetName())
+			}
+		}
+	}
+	return nil
+}
+
+// saveSnapshot saves the spec of canvas in a ConfigMap of its home
+// namespace, which outlives the canvas.
+func (r *Reconciler) saveSnapshot(ctx context.Context, canvas *v1alpha1.Canvas, log *logging.Logger) error {
+	snapshot := &v1alpha1.Canvas{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: v1alpha1.GroupVersion.String(),
+			Kind:       "Canvas",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        canvas.Name,
+			Labels:      canvas.Labels,
+			Annotations: canvas.Annotations,
+		},
+		Spec: canvas.Spec,
+	}
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: snapshotName, Namespace: canvas.HomeNamespace()},
+	}
+	result, err := controllerutil.CreateOrUpdate(ctx, r.Client, cm, func() error {
+		// No owner reference, so that the snapshot is not garbage collected.
+		setManagedBy(&cm.ObjectMeta)
+		cm.Data = map[string]string{snapshotKey: string(data)}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if result != controllerutil.OperationResultNone {
+		log.Info("Saved canvas snapshot", "name", cm.Name, "operation", result)
+		r.Recorder.Eventf(canvas, cm, corev1.EventTypeNormal, eventReasonSnapshotSaved, actionDelete,
+			"Saved canvas in ConfigMap %s/%s", cm.Namespace, cm.Name)
+	}
+	return nil
+}
+
+// setTerminating sets the Terminating condition of canvas and saves its
+// status if the condition changed.
+func (r *Reconciler) setTerminating(ctx context.Context, canvas *v1alpha1.Canvas, reason, message string) error {
+	changed := meta.SetStatusCondition(&canvas.Status.Conditions, metav1.Condition{
+		Type:               v1alpha1.ConditionTypeTerminating,
+		Status:             metav1.ConditionTrue,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: canvas.Generation,
+	})
+	if !changed {
+		return nil
+	}
+	return r.Status().Update(ctx, canvas)
+}
+
+// failTerminating records in the Terminating condition of canvas and in a
+// warning event that a step of its deletion failed with err, and returns err.
+func (r *Reconciler) failTerminating(
+	ctx context.Context, canvas *v1alpha1.Canvas, message string, err error, log *logging.Logger,
+) error {
+	message = fmt.Sprintf("%s: %v", message, err)
+	r.Recorder.Eventf(canvas, nil, corev1.EventTypeWarning, eventReasonCleanupFailed, actionDelete, "%s", message)
+	if statusErr := r.setTerminating(ctx, canvas, v1alpha1.ReasonFailed, message); statusErr != nil {
+		log.Error(statusErr, "Failed to update status to Failed")
+	}
+	return err
+}
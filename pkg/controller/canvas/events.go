@@ -0,0 +1,29 @@
+package canvas
+
+// Actions of the events emitted for canvases.
+const (
+	actionProvision = "Provision"
+	actionDelete    = "Delete"
+)
+
+// Reasons of the events emitted for canvases.
+const (
+	eventReasonFinalizerAdded     = "FinalizerAdded"
+	eventReasonFinalizerRemoved   = "FinalizerRemoved"
+	eventReasonFinalizerFailed    = "FinalizerFailed"
+	eventReasonNamespaceCreated   = "NamespaceCreated"
+	eventReasonNamespaceAdopted   = "NamespaceAdopted"
+	eventReasonNamespaceUpdated   = "NamespaceUpdated"
+	eventReasonNamespaceReleased  = "NamespaceReleased"
+	eventReasonNamespaceConflict  = "NamespaceConflict"
+	eventReasonNamespaceFailed    = "NamespaceFailed"
+	eventReasonRBACFailed         = "RBACFailed"
+	eventReasonRBACSkipped        = "RBACSkipped"
+	eventReasonQuotaFailed        = "QuotaFailed"
+	eventReasonNetworkFailed      = "NetworkPolicyFailed"
+	eventReasonStatusUpdateFailed = "StatusUpdateFailed"
+	eventReasonProvisioned        = "Provisioned"
+	eventReasonDeletionBlocked    = "DeletionBlocked"
+	eventReasonSnapshotSaved      = "SnapshotSaved"
+	eventReasonCleanupFailed      = "CleanupFailed"
+)
@@ -0,0 +1,278 @@
+package canvas
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/orray-proj/orray/api/v1alpha1"
+	"github.com/orray-proj/orray/pkg/logging"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// adoptionPolicy returns the adoption policy of canvas.
+func adoptionPolicy(canvas *v1alpha1.Canvas) v1alpha1.AdoptionPolicy {
+	if canvas.Spec.AdoptionPolicy == "" {
+		return v1alpha1.AdoptionPolicyRefuse
+	}
+	return canvas.Spec.AdoptionPolicy
+}
+
+// isControlledBy reports whether canvas is the controller of ns.
+func isControlledBy(ns *corev1.Namespace, canvas *v1alpha1.Canvas) bool {
+	owner := metav1.GetControllerOf(ns)
+	return owner != nil && owner.Kind == "Canvas" && owner.Name == canvas.Name && owner.UID == canvas.UID
+}
+
+// syncNamespace makes sure the home namespace of canvas exists, adopting an
+// existing one according to the adoption policy of canvas. It returns the
+// NamespaceReady condition of canvas.
+func (r *Reconciler) syncNamespace(
+	ctx context.Context, canvas *v1alpha1.Canvas, log *logging.Logger,
+) (metav1.Condition, error) {
+	condition := metav1.Condition{
+		Type:               v1alpha1.ConditionTypeNamespaceReady,
+		Status:             metav1.ConditionTrue,
+		Reason:             v1alpha1.ReasonSynced,
+		Message:            "Namespace is owned by the canvas",
+		ObservedGeneration: canvas.Generation,
+	}
+	fail := func(reason string, err error) (metav1.Condition, error) {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = reason
+		condition.Message = err.Error()
+		return condition, err
+	}
+
+	ns := &corev1.Namespace{}
+	err := r.Get(ctx, client.ObjectKey{Name: canvas.HomeNamespace()}, ns)
+	if errors.IsNotFound(err) {
+		log.Info("Creating namespace for canvas", "name", canvas.HomeNamespace())
+		ns = &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: canvas.HomeNamespace(),
+				Annotations: map[string]string{
+					v1alpha1.AnnotationCanvas:    canvas.Name,
+					v1alpha1.AnnotationManagedBy: v1alpha1.ManagedByValue,
+				},
+			},
+		}
+		// Set controller reference so K8s GC deletes it when Canvas is gone
+		if err := controllerutil.SetControllerReference(canvas, ns, r.Scheme()); err != nil {
+			return fail(v1alpha1.ReasonFailed, err)
+		}
+		if err := r.Create(ctx, ns); err != nil {
+			return fail(v1alpha1.ReasonFailed, err)
+		}
+		r.Recorder.Eventf(canvas, ns, corev1.EventTypeNormal, eventReasonNamespaceCreated, actionProvision,
+			"Created namespace %s", ns.Name)
+		return condition, nil
+	}
+	if err != nil {
+		return fail(v1alpha1.ReasonFailed, err)
+	}
+
+	policy := adoptionPolicy(canvas)
+	owned := isControlledBy(ns, canvas)
+	// The adopted mark only counts when it was left by this canvas.
+	markedBy := ns.Annotations[v1alpha1.AnnotationCanvas]
+	adopted := ns.Annotations[v1alpha1.AnnotationAdopted] == "true" && markedBy == canvas.Name
+	adopting := false
+	if !owned && !adopted {
+		if markedBy != "" && markedBy != canvas.Name {
+			return fail(v1alpha1.ReasonNamespaceConflict, fmt.Errorf(
+				"namespace %s already belongs to canvas %s", ns.Name, markedBy))
+		}
+		// The namespace existed before the canvas.
+		if owner := metav1.GetControllerOf(ns); owner != nil {
+			return fail(v1alpha1.ReasonNamespaceConflict, fmt.Errorf(
+				"namespace %s already exists and is controlled by %s %s", ns.Name, owner.Kind, owner.Name))
+		}
+		if policy == v1alpha1.AdoptionPolicyRefuse {
+			return fail(v1alpha1.ReasonNamespaceConflict, fmt.Errorf(
+				"namespace %s already exists and the adoption policy is %s", ns.Name, policy))
+		}
+		log.Info("Adopting existing namespace", "name", ns.Name, "policy", policy)
+		adopted = true
+		adopting = true
+	}
+
+	// Namespace exists, ensure annotations are correct
+	if ns.Annotations == nil {
+		ns.Annotations = make(map[string]string)
+	}
+
+	updated := false
+	wanted := map[string]string{
+		v1alpha1.AnnotationCanvas:    canvas.Name,
+		v1alpha1.AnnotationManagedBy: v1alpha1.ManagedByValue,
+	}
+	if adopted {
+		wanted[v1alpha1.AnnotationAdopted] = "true"
+	}
+	for key, value := range wanted {
+		if ns.Annotations[key] != value {
+			ns.Annotations[key] = value
+			updated = true
+		}
+	}
+
+	// Only adopted namespaces change hands with the policy. Those created by
+	// the canvas always belong to it.
+	if adopted {
+		switch {
+		case policy == v1alpha1.AdoptionPolicyAdoptAndOwn && !owned:
+			if err := controllerutil.SetControllerReference(canvas, ns, r.Scheme()); err != nil {
+				return fail(v1alpha1.ReasonFailed, err)
+			}
+			updated = true
+		case policy != v1alpha1.AdoptionPolicyAdoptAndOwn && owned:
+			if err := controllerutil.RemoveControllerReference(canvas, ns, r.Scheme()); err != nil {
+				return fail(v1alpha1.ReasonFailed, err)
+			}
+			updated = true
+		}
+
+		condition.Reason = v1alpha1.ReasonAdopted
+		condition.Message = "Namespace was adopted and is kept when the canvas is deleted"
+		if policy == v1alpha1.AdoptionPolicyAdoptAndOwn {
+			condition.Message = "Namespace was adopted and is deleted along with the canvas"
+		}
+	}
+
+	if updated {
+		log.Info("Updating namespace", "name", ns.Name)
+		if err := r.Update(ctx, ns); err != nil {
+			return fail(v1alpha1.ReasonFailed, err)
+		}
+		if adopting {
+			r.Recorder.Eventf(canvas, ns, corev1.EventTypeNormal, eventReasonNamespaceAdopted, actionProvision,
+				"Adopted existing namespace %s (%s)", ns.Name, policy)
+		} else {
+			r.Recorder.Eventf(canvas, ns, corev1.EventTypeNormal, eventReasonNamespaceUpdated, actionProvision,
+				"Repaired the annotations and owner of namespace %s", ns.Name)
+		}
+	}
+	return condition, nil
+}
+
+// releaseNamespace removes the marks of canvas from its home namespace,
+// unless the namespace is deleted along with canvas.
+func (r *Reconciler) releaseNamespace(ctx context.Context, canvas *v1alpha1.Canvas, log *logging.Logger) error {
+	ns := &corev1.Namespace{}
+	if err := r.Get(ctx, client.ObjectKey{Name: canvas.HomeNamespace()}, ns); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	owned := isControlledBy(ns, canvas)
+	if owned && deletionPolicy(canvas) == v1alpha1.DeletionPolicyDelete {
+		return nil
+	}
+	if !owned && ns.Annotations[v1alpha1.AnnotationCanvas] != canvas.Name {
+		// The namespace was never adopted by canvas.
+		return nil
+	}
+
+	log.Info("Releasing namespace", "name", ns.Name)
+	if owned {
+		if err := controllerutil.RemoveControllerReference(canvas, ns, r.Scheme()); err != nil {
+			return err
+		}
+	}
+	delete(ns.Annotations, v1alpha1.AnnotationCanvas)
+	delete(ns.Annotations, v1alpha1.AnnotationManagedBy)
+	delete(ns.Annotations, v1alpha1.AnnotationAdopted)
+	if err := r.Update(ctx, ns); err != nil {
+		return err
+	}
+	r.Recorder.Eventf(canvas, ns, corev1.EventTypeNormal, eventReasonNamespaceReleased, actionDelete,
+		"Released namespace %s", ns.Name)
+	return nil
+}
+
+// resolveNamespaces returns the sorted names of the existing namespaces
+// canvas spans, including its home namespace. Namespaces listed by name that
+// do not exist are skipped.
+func (r *Reconciler) resolveNamespaces(
+	ctx context.Context, canvas *v1alpha1.Canvas, log *logging.Logger,
+) ([]string, error) {
+	names := []string{canvas.HomeNamespace()}
+	for _, source := range canvas.Spec.Namespaces {
+		if source.Selector == nil {
+			err := r.Get(ctx, client.ObjectKey{Name: source.Name}, &corev1.Namespace{})
+			if errors.IsNotFound(err) {
+				log.Debug("Skipping missing namespace", "name", source.Name)
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to get namespace %s: %w", source.Name, err)
+			}
+			names = append(names, source.Name)
+			continue
+		}
+
+		selector, err := metav1.LabelSelectorAsSelector(source.Selector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid namespace selector: %w", err)
+		}
+		list := &corev1.NamespaceList{}
+		if err := r.List(ctx, list, client.MatchingLabelsSelector{Selector: selector}); err != nil {
+			return nil, fmt.Errorf("failed to list namespaces: %w", err)
+		}
+		for _, ns := range list.Items {
+			names = append(names, ns.Name)
+		}
+	}
+	slices.Sort(names)
+	return slices.Compact(names), nil
+}
+
+// spansNamespace reports whether canvas spans ns, or did when it was last
+// reconciled.
+func spansNamespace(canvas *v1alpha1.Canvas, ns *corev1.Namespace) bool {
+	if ns.Name == canvas.HomeNamespace() || slices.Contains(canvas.Status.Namespaces, ns.Name) {
+		return true
+	}
+	for _, source := range canvas.Spec.Namespaces {
+		if source.Selector == nil {
+			if source.Name == ns.Name {
+				return true
+			}
+			continue
+		}
+		selector, err := metav1.LabelSelectorAsSelector(source.Selector)
+		if err == nil && selector.Matches(labels.Set(ns.Labels)) {
+			return true
+		}
+	}
+	return false
+}
+
+// canvasesForNamespace maps a namespace to the canvases spanning it, so that
+// their status follows the namespaces being created, relabeled or deleted.
+func (r *Reconciler) canvasesForNamespace(ctx context.Context, obj client.Object) []reconcile.Request {
+	ns, ok := obj.(*corev1.Namespace)
+	if !ok {
+		return nil
+	}
+	list := &v1alpha1.CanvasList{}
+	if err := r.List(ctx, list); err != nil {
+		r.Logger.WithContext(ctx).Error(err, "Failed to list canvases", "namespace", ns.Name)
+		return nil
+	}
+	var requests []reconcile.Request
+	for i := range list.Items {
+		if spansNamespace(&list.Items[i], ns) {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: list.Items[i].Name},
+			})
+		}
+	}
+	return requests
+}
@@ -0,0 +1,154 @@
+package canvas
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/orray-proj/orray/api/v1alpha1"
+	"github.com/orray-proj/orray/pkg/logging"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// networkPolicyName is the name of the NetworkPolicy isolating a canvas
+// namespace.
+const networkPolicyName = "orray-isolation"
+
+// networkIsolation returns the isolation mode of canvas.
+func networkIsolation(canvas *v1alpha1.Canvas) v1alpha1.NetworkIsolation {
+	if canvas.Spec.Network == nil || canvas.Spec.Network.Isolation == "" {
+		return v1alpha1.NetworkIsolationOpen
+	}
+	return canvas.Spec.Network.Isolation
+}
+
+// ingressPeers returns the sources of the traffic an isolated canvas accepts,
+// given the other namespaces it accepts traffic from.
+func ingressPeers(canvas *v1alpha1.Canvas, namespaces []string) []networkingv1.NetworkPolicyPeer {
+	var peers []networkingv1.NetworkPolicyPeer
+	if networkIsolation(canvas) == v1alpha1.NetworkIsolationNamespaceIsolated {
+		// A pod selector without a namespace selector selects the pods of
+		// the policy's namespace.
+		peers = append(peers, networkingv1.NetworkPolicyPeer{PodSelector: &metav1.LabelSelector{}})
+	}
+
+	if len(namespaces) > 0 {
+		peers = append(peers, networkingv1.NetworkPolicyPeer{
+			NamespaceSelector: &metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{{
+					Key:      corev1.LabelMetadataName,
+					Operator: metav1.LabelSelectorOpIn,
+					Values:   namespaces,
+				}},
+			},
+		})
+	}
+	return peers
+}
+
+// allowedNamespaces returns the sorted names of the namespaces, other than its
+// home namespace, an isolated canvas accepts traffic from.
+func (r *Reconciler) allowedNamespaces(ctx context.Context, canvas *v1alpha1.Canvas) ([]string, error) {
+	namespaces := slices.Clone(canvas.Spec.Network.AllowedNamespaces)
+	if networkIsolation(canvas) == v1alpha1.NetworkIsolationNamespaceIsolated {
+		// The workloads of a canvas may reach each other across its
+		// namespaces.
+		namespaces = append(namespaces, canvas.Status.Namespaces...)
+	}
+	for _, name := range canvas.Spec.Network.AllowedCanvases {
+		allowed := &v1alpha1.Canvas{}
+		err := r.Get(ctx, client.ObjectKey{Name: name}, allowed)
+		if errors.IsNotFound(err) {
+			// Whoever owns a namespace named after a missing canvas must not
+			// be let in. The canvas is requeued once its peer is created.
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get allowed canvas %s: %w", name, err)
+		}
+		namespaces = append(namespaces, allowed.HomeNamespace())
+		namespaces = append(namespaces, allowed.Status.Namespaces...)
+	}
+
+	namespaces = slices.DeleteFunc(namespaces, func(ns string) bool { return ns == canvas.HomeNamespace() })
+	slices.Sort(namespaces)
+	return slices.Compact(namespaces), nil
+}
+
+// canvasesAllowingCanvas maps a canvas to the canvases accepting traffic from
+// it, so that their network policies follow its namespaces.
+func (r *Reconciler) canvasesAllowingCanvas(ctx context.Context, obj client.Object) []reconcile.Request {
+	peer, ok := obj.(*v1alpha1.Canvas)
+	if !ok {
+		return nil
+	}
+	list := &v1alpha1.CanvasList{}
+	if err := r.List(ctx, list); err != nil {
+		r.Logger.WithContext(ctx).Error(err, "Failed to list canvases", "peer", peer.Name)
+		return nil
+	}
+	var requests []reconcile.Request
+	for _, canvas := range list.Items {
+		if canvas.Spec.Network != nil && slices.Contains(canvas.Spec.Network.AllowedCanvases, peer.Name) {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: canvas.Name},
+			})
+		}
+	}
+	return requests
+}
+
+// syncNetworkPolicy makes sure the namespace of canvas holds the
+// NetworkPolicy enforcing its isolation mode. It reports whether the
+// namespace is isolated.
+func (r *Reconciler) syncNetworkPolicy(
+	ctx context.Context, canvas *v1alpha1.Canvas, log *logging.Logger,
+) (bool, error) {
+	policy := &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: networkPolicyName, Namespace: canvas.HomeNamespace()},
+	}
+
+	isolation := networkIsolation(canvas)
+	if isolation == v1alpha1.NetworkIsolationOpen {
+		deleted, err := r.deleteIfExists(ctx, policy)
+		if deleted {
+			log.Info("Deleted network policy", "name", policy.Name)
+		}
+		if err != nil {
+			return false, fmt.Errorf("failed to delete network policy: %w", err)
+		}
+		return false, nil
+	}
+
+	namespaces, err := r.allowedNamespaces(ctx, canvas)
+	if err != nil {
+		return false, err
+	}
+	result, err := controllerutil.CreateOrUpdate(ctx, r.Client, policy, func() error {
+		setManagedBy(&policy.ObjectMeta)
+		policy.Spec = networkingv1.NetworkPolicySpec{
+			// An empty pod selector selects every pod of the namespace.
+			PodSelector: metav1.LabelSelector{},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+		}
+		// A policy without ingress rules denies all ingress traffic.
+		if peers := ingressPeers(canvas, namespaces); len(peers) > 0 {
+			policy.Spec.Ingress = []networkingv1.NetworkPolicyIngressRule{{From: peers}}
+		}
+		return controllerutil.SetControllerReference(canvas, policy, r.Scheme())
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to sync network policy: %w", err)
+	}
+	if result != controllerutil.OperationResultNone {
+		log.Info("Synced network policy", "name", policy.Name, "isolation", isolation, "operation", result)
+	}
+	return true, nil
+}
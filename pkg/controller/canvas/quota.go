@@ -0,0 +1,104 @@
+package canvas
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/orray-proj/orray/api/v1alpha1"
+	"github.com/orray-proj/orray/pkg/logging"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	// resourceQuotaName is the name of the ResourceQuota of a canvas
+	// namespace.
+	resourceQuotaName = "orray-quota"
+	// limitRangeName is the name of the LimitRange of a canvas namespace.
+	limitRangeName = "orray-limits"
+)
+
+// syncQuota makes sure the namespace of canvas holds the ResourceQuota and
+// LimitRange described by its spec, and records the usage of the quota in its
+// status.
+func (r *Reconciler) syncQuota(ctx context.Context, canvas *v1alpha1.Canvas, log *logging.Logger) error {
+	resources := canvas.Spec.Resources
+	if resources == nil {
+		resources = &v1alpha1.CanvasResources{}
+	}
+
+	quota, err := r.syncResourceQuota(ctx, canvas, resources, log)
+	if err != nil {
+		return fmt.Errorf("failed to sync resource quota: %w", err)
+	}
+	if err := r.syncLimitRange(ctx, canvas, resources, log); err != nil {
+		return fmt.Errorf("failed to sync limit range: %w", err)
+	}
+
+	canvas.Status.Quota = nil
+	if quota != nil {
+		// The usage is computed by the quota controller, so it lags behind
+		// the creation of the quota. Changes of the usage trigger another
+		// reconciliation.
+		canvas.Status.Quota = &v1alpha1.CanvasQuotaStatus{
+			Hard: quota.Status.Hard,
+			Used: quota.Status.Used,
+		}
+	}
+	return nil
+}
+
+func (r *Reconciler) syncResourceQuota(
+	ctx context.Context, canvas *v1alpha1.Canvas, resources *v1alpha1.CanvasResources, log *logging.Logger,
+) (*corev1.ResourceQuota, error) {
+	quota := &corev1.ResourceQuota{
+		ObjectMeta: metav1.ObjectMeta{Name: resourceQuotaName, Namespace: canvas.HomeNamespace()},
+	}
+	if len(resources.Quota) == 0 {
+		deleted, err := r.deleteIfExists(ctx, quota)
+		if deleted {
+			log.Info("Deleted resource quota", "name", quota.Name)
+		}
+		return nil, err
+	}
+
+	result, err := controllerutil.CreateOrUpdate(ctx, r.Client, quota, func() error {
+		setManagedBy(&quota.ObjectMeta)
+		quota.Spec.Hard = resources.Quota
+		return controllerutil.SetControllerReference(canvas, quota, r.Scheme())
+	})
+	if result != controllerutil.OperationResultNone {
+		log.Info("Synced resource quota", "name", quota.Name, "operation", result)
+	}
+	return quota, err
+}
+
+func (r *Reconciler) syncLimitRange(
+	ctx context.Context, canvas *v1alpha1.Canvas, resources *v1alpha1.CanvasResources, log *logging.Logger,
+) error {
+	limitRange := &corev1.LimitRange{
+		ObjectMeta: metav1.ObjectMeta{Name: limitRangeName, Namespace: canvas.HomeNamespace()},
+	}
+	if len(resources.DefaultRequests) == 0 && len(resources.DefaultLimits) == 0 {
+		deleted, err := r.deleteIfExists(ctx, limitRange)
+		if deleted {
+			log.Info("Deleted limit range", "name", limitRange.Name)
+		}
+		return err
+	}
+
+	result, err := controllerutil.CreateOrUpdate(ctx, r.Client, limitRange, func() error {
+		setManagedBy(&limitRange.ObjectMeta)
+		limitRange.Spec.Limits = []corev1.LimitRangeItem{{
+			Type:           corev1.LimitTypeContainer,
+			Default:        resources.DefaultLimits,
+			DefaultRequest: resources.DefaultRequests,
+		}}
+		return controllerutil.SetControllerReference(canvas, limitRange, r.Scheme())
+	})
+	if result != controllerutil.OperationResultNone {
+		log.Info("Synced limit range", "name", limitRange.Name, "operation", result)
+	}
+	return err
+}
@@ -0,0 +1,139 @@
+package canvas
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/orray-proj/orray/api/v1alpha1"
+	"github.com/orray-proj/orray/pkg/logging"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// roleName returns the name of the RoleBinding of a canvas role.
+func roleName(role v1alpha1.CanvasRole) string {
+	return "orray-" + string(role)
+}
+
+// clusterRoleName returns the name of the ClusterRole, shipped with the chart,
+// holding the permissions of a canvas role. The controller may only bind
+// these ClusterRoles, so it never grants more than they do.
+func clusterRoleName(role v1alpha1.CanvasRole) string {
+	return "orray-canvas-" + string(role)
+}
+
+// roleSubjects returns the members of canvas granted role.
+func roleSubjects(canvas *v1alpha1.Canvas, role v1alpha1.CanvasRole) []rbacv1.Subject {
+	var subjects []rbacv1.Subject
+	for _, member := range canvas.Spec.Members {
+		if member.Role != role {
+			continue
+		}
+		subject := rbacv1.Subject{Kind: member.Kind, Name: member.Name}
+		if member.Kind == v1alpha1.MemberKindServiceAccount {
+			subject.Namespace = member.Namespace
+			if subject.Namespace == "" {
+				subject.Namespace = canvas.HomeNamespace()
+			}
+		} else {
+			subject.APIGroup = rbacv1.GroupName
+		}
+		subjects = append(subjects, subject)
+	}
+	return subjects
+}
+
+// syncRBAC makes sure the namespace of canvas holds a RoleBinding granting
+// each canvas role to the members with that role. It returns the RBACReady
+// condition of canvas.
+func (r *Reconciler) syncRBAC(
+	ctx context.Context, canvas *v1alpha1.Canvas, log *logging.Logger,
+) (metav1.Condition, error) {
+	condition := metav1.Condition{
+		Type:               v1alpha1.ConditionTypeRBACReady,
+		Status:             metav1.ConditionTrue,
+		Reason:             v1alpha1.ReasonSynced,
+		Message:            fmt.Sprintf("Access granted to %d members", len(canvas.Spec.Members)),
+		ObservedGeneration: canvas.Generation,
+	}
+	fail := func(err error) (metav1.Condition, error) {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = v1alpha1.ReasonFailed
+		condition.Message = err.Error()
+		return condition, err
+	}
+
+	ns := &corev1.Namespace{}
+	if err := r.Get(ctx, client.ObjectKey{Name: canvas.HomeNamespace()}, ns); err != nil {
+		return fail(fmt.Errorf("failed to get namespace %s: %w", canvas.HomeNamespace(), err))
+	}
+	if !isControlledBy(ns, canvas) || ns.Annotations[v1alpha1.AnnotationAdopted] == "true" {
+		// Granting access to a namespace the canvas did not create would let
+		// anyone who can edit canvases take over existing namespaces.
+		log.Info("Not granting access to a namespace the canvas did not create", "name", ns.Name)
+		if len(canvas.Spec.Members) > 0 {
+			r.Recorder.Eventf(canvas, ns, corev1.EventTypeWarning, eventReasonRBACSkipped, actionProvision,
+				"Not granting access to namespace %s, which the canvas did not create", ns.Name)
+		}
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = v1alpha1.ReasonNamespaceNotOwned
+		condition.Message = fmt.Sprintf("Access is not granted to namespace %s, which the canvas did not create", ns.Name)
+		return condition, nil
+	}
+
+	for _, role := range v1alpha1.CanvasRoles {
+		if err := r.syncRoleBinding(ctx, canvas, role, log); err != nil {
+			return fail(fmt.Errorf("failed to sync role binding %s: %w", roleName(role), err))
+		}
+	}
+	return condition, nil
+}
+
+func (r *Reconciler) syncRoleBinding(
+	ctx context.Context, canvas *v1alpha1.Canvas, role v1alpha1.CanvasRole, log *logging.Logger,
+) error {
+	subjects := roleSubjects(canvas, role)
+	obj := &rbacv1.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{Name: roleName(role), Namespace: canvas.HomeNamespace()},
+	}
+
+	if len(subjects) == 0 {
+		// Nobody has the role anymore.
+		deleted, err := r.deleteIfExists(ctx, obj)
+		if deleted {
+			log.Info("Deleted role binding", "name", obj.Name)
+		}
+		return err
+	}
+
+	roleRef := rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: clusterRoleName(role)}
+	if err := r.Get(ctx, client.ObjectKeyFromObject(obj), obj); err == nil {
+		if !equality.Semantic.DeepEqual(obj.RoleRef, roleRef) {
+			// The role of a binding cannot be changed, so it is replaced.
+			if err := r.Delete(ctx, obj); client.IgnoreNotFound(err) != nil {
+				return err
+			}
+			obj = &rbacv1.RoleBinding{
+				ObjectMeta: metav1.ObjectMeta{Name: roleName(role), Namespace: canvas.HomeNamespace()},
+			}
+		}
+	} else if !errors.IsNotFound(err) {
+		return err
+	}
+
+	result, err := controllerutil.CreateOrUpdate(ctx, r.Client, obj, func() error {
+		setManagedBy(&obj.ObjectMeta)
+		obj.RoleRef = roleRef
+		obj.Subjects = subjects
+		return controllerutil.SetControllerReference(canvas, obj, r.Scheme())
+	})
+	if result != controllerutil.OperationResultNone {
+		log.Info("Synced role binding", "name", obj.Name, "subjects", len(subjects), "operation", result)
+	}
+	return err
+}
@@ -6,25 +6,53 @@ import (
 
 	"github.com/orray-proj/orray/api/v1alpha1"
 	"github.com/orray-proj/orray/pkg/logging"
+	"github.com/orray-proj/orray/pkg/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/events"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 )
 
 // Reconciler reconciles a Canvas object
 type Reconciler struct {
 	client.Client
 	Logger *logging.Logger
+	// Recorder emits the events describing the provisioning and deletion of
+	// canvases.
+	Recorder events.EventRecorder
+	// APIReader reads objects the controller does not cache, such as the
+	// pods of a protected canvas being deleted.
+	APIReader client.Reader
 }
 
+// tracerName is the instrumentation scope of the canvas controller spans.
+const tracerName = "github.com/orray-proj/orray/pkg/controller/canvas"
+
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
 func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
-	log := r.Logger.WithValues("canvas", req.NamespacedName)
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "canvas.Reconcile",
+		trace.WithAttributes(attribute.String("orray.canvas.name", req.Name)),
+	)
+	defer span.End()
+
+	result, err := r.reconcile(ctx, req)
+	tracing.RecordError(span, err)
+	return result, err
+}
+
+func (r *Reconciler) reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := r.Logger.WithContext(ctx).WithValues("canvas", req.NamespacedName)
 
 	canvas := &v1alpha1.Canvas{}
 	if err := r.Get(ctx, req.NamespacedName, canvas); err != nil {
@@ -45,8 +73,12 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	if controllerutil.AddFinalizer(canvas, v1alpha1.FinalizerCanvas) {
 		if err := r.Update(ctx, canvas); err != nil {
 			log.Error(err, "Failed to add finalizer")
+			r.Recorder.Eventf(canvas, nil, corev1.EventTypeWarning, eventReasonFinalizerFailed, actionProvision,
+				"Failed to add finalizer: %v", err)
 			return ctrl.Result{}, err
 		}
+		r.Recorder.Eventf(canvas, nil, corev1.EventTypeNormal, eventReasonFinalizerAdded, actionProvision,
+			"Added finalizer %s", v1alpha1.FinalizerCanvas)
 	}
 
 	// Update status to Provisioning if not already
@@ -65,21 +97,74 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	}
 
 	// Sync Namespace
-	if err := r.syncNamespace(ctx, canvas, log); err != nil {
+	namespaceCondition, err := r.syncNamespace(ctx, canvas, log)
+	meta.SetStatusCondition(&canvas.Status.Conditions, namespaceCondition)
+	if err != nil {
+		log.Error(err, "Failed to sync namespace")
+		reason := eventReasonNamespaceFailed
+		if namespaceCondition.Reason == v1alpha1.ReasonNamespaceConflict {
+			reason = eventReasonNamespaceConflict
+		}
+		r.markFailed(ctx, canvas, reason, fmt.Sprintf("Failed to sync namespace: %v", err), log)
+		return ctrl.Result{}, err
+	}
+
+	// Track the namespaces the canvas spans
+	namespaces, err := r.resolveNamespaces(ctx, canvas, log)
+	if err != nil {
+		log.Error(err, "Failed to resolve namespaces")
+		r.markFailed(ctx, canvas, eventReasonNamespaceFailed, fmt.Sprintf("Failed to resolve namespaces: %v", err), log)
+		return ctrl.Result{}, err
+	}
+	canvas.Status.Namespaces = namespaces
+
+	// Sync RBAC
+	rbacCondition, err := r.syncRBAC(ctx, canvas, log)
+	meta.SetStatusCondition(&canvas.Status.Conditions, rbacCondition)
+	if err != nil {
+		log.Error(err, "Failed to sync RBAC")
+		r.markFailed(ctx, canvas, eventReasonRBACFailed, fmt.Sprintf("Failed to sync RBAC: %v", err), log)
+		return ctrl.Result{}, err
+	}
+
+	// Sync ResourceQuota and LimitRange
+	if err := r.syncQuota(ctx, canvas, log); err != nil {
+		log.Error(err, "Failed to sync quota")
+		r.markFailed(ctx, canvas, eventReasonQuotaFailed, fmt.Sprintf("Failed to sync quota: %v", err), log)
+		return ctrl.Result{}, err
+	}
+
+	// Sync NetworkPolicy
+	isolated, err := r.syncNetworkPolicy(ctx, canvas, log)
+	if err != nil {
+		log.Error(err, "Failed to sync network policy")
 		meta.SetStatusCondition(&canvas.Status.Conditions, metav1.Condition{
-			Type:               v1alpha1.ConditionTypeReady,
+			Type:               v1alpha1.ConditionTypeNetworkIsolated,
 			Status:             metav1.ConditionFalse,
 			Reason:             v1alpha1.ReasonFailed,
-			Message:            fmt.Sprintf("Failed to sync namespace: %v", err),
+			Message:            err.Error(),
 			ObservedGeneration: canvas.Generation,
 		})
-		if statusErr := r.Status().Update(ctx, canvas); statusErr != nil {
-			log.Error(statusErr, "Failed to update status to Failed")
-		}
+		r.markFailed(ctx, canvas, eventReasonNetworkFailed,
+			fmt.Sprintf("Failed to sync network isolation: %v", err), log)
 		return ctrl.Result{}, err
 	}
+	networkCondition := metav1.Condition{
+		Type:               v1alpha1.ConditionTypeNetworkIsolated,
+		Status:             metav1.ConditionFalse,
+		Reason:             v1alpha1.ReasonIsolationDisabled,
+		Message:            "Traffic is accepted from anywhere",
+		ObservedGeneration: canvas.Generation,
+	}
+	if isolated {
+		networkCondition.Status = metav1.ConditionTrue
+		networkCondition.Reason = v1alpha1.ReasonSynced
+		networkCondition.Message = fmt.Sprintf("Ingress traffic is restricted (%s)", networkIsolation(canvas))
+	}
+	meta.SetStatusCondition(&canvas.Status.Conditions, networkCondition)
 
 	// Update status to Ready
+	wasReady := meta.IsStatusConditionTrue(canvas.Status.Conditions, v1alpha1.ConditionTypeReady)
 	meta.SetStatusCondition(&canvas.Status.Conditions, metav1.Condition{
 		Type:               v1alpha1.ConditionTypeReady,
 		Status:             metav1.ConditionTrue,
@@ -91,80 +176,67 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 
 	if err := r.Status().Update(ctx, canvas); err != nil {
 		log.Error(err, "Failed to update status to Ready")
+		r.Recorder.Eventf(canvas, nil, corev1.EventTypeWarning, eventReasonStatusUpdateFailed, actionProvision,
+			"Failed to update status: %v", err)
 		return ctrl.Result{}, err
 	}
+	if !wasReady {
+		r.Recorder.Eventf(canvas, nil, corev1.EventTypeNormal, eventReasonProvisioned, actionProvision,
+			"Canvas provisioned successfully")
+	}
 
 	return ctrl.Result{}, nil
 }
 
-func (r *Reconciler) syncNamespace(ctx context.Context, canvas *v1alpha1.Canvas, log *logging.Logger) error {
-	ns := &corev1.Namespace{}
-	err := r.Get(ctx, client.ObjectKey{Name: canvas.Name}, ns)
-	if err != nil {
-		if errors.IsNotFound(err) {
-			log.Info("Creating namespace for canvas", "name", canvas.Name)
-			ns = &corev1.Namespace{
-				ObjectMeta: metav1.ObjectMeta{
-					Name: canvas.Name,
-					Annotations: map[string]string{
-						v1alpha1.AnnotationCanvas:    "true",
-						v1alpha1.AnnotationManagedBy: v1alpha1.ManagedByValue,
-					},
-				},
-			}
-			// Set controller reference so K8s GC deletes it when Canvas is gone
-			if err := controllerutil.SetControllerReference(canvas, ns, r.Scheme()); err != nil {
-				return err
-			}
-			return r.Create(ctx, ns)
-		}
-		return err
-	}
-
-	// Namespace exists, ensure annotations are correct
-	if ns.Annotations == nil {
-		ns.Annotations = make(map[string]string)
-	}
-
-	updated := false
-	if ns.Annotations[v1alpha1.AnnotationCanvas] != "true" {
-		ns.Annotations[v1alpha1.AnnotationCanvas] = "true"
-		updated = true
-	}
-	if ns.Annotations[v1alpha1.AnnotationManagedBy] != v1alpha1.ManagedByValue {
-		ns.Annotations[v1alpha1.AnnotationManagedBy] = v1alpha1.ManagedByValue
-		updated = true
-	}
-
-	if updated {
-		log.Info("Updating namespace annotations", "name", canvas.Name)
-		return r.Update(ctx, ns)
+// markFailed sets the Ready condition of canvas to False with message, saves
+// its status and emits a warning event with reason.
+func (r *Reconciler) markFailed(
+	ctx context.Context, canvas *v1alpha1.Canvas, reason, message string, log *logging.Logger,
+) {
+	r.Recorder.Eventf(canvas, nil, corev1.EventTypeWarning, reason, actionProvision, "%s", message)
+	meta.SetStatusCondition(&canvas.Status.Conditions, metav1.Condition{
+		Type:               v1alpha1.ConditionTypeReady,
+		Status:             metav1.ConditionFalse,
+		Reason:             v1alpha1.ReasonFailed,
+		Message:            message,
+		ObservedGeneration: canvas.Generation,
+	})
+	if err := r.Status().Update(ctx, canvas); err != nil {
+		log.Error(err, "Failed to update status to Failed")
 	}
-
-	return nil
 }
 
-func (r *Reconciler) reconcileDelete(ctx context.Context, canvas *v1alpha1.Canvas, log *logging.Logger) (ctrl.Result, error) {
-	if !controllerutil.ContainsFinalizer(canvas, v1alpha1.FinalizerCanvas) {
-		return ctrl.Result{}, nil
+// setManagedBy marks an object as managed by the controller.
+func setManagedBy(obj *metav1.ObjectMeta) {
+	if obj.Annotations == nil {
+		obj.Annotations = make(map[string]string)
 	}
+	obj.Annotations[v1alpha1.AnnotationManagedBy] = v1alpha1.ManagedByValue
+}
 
-	// Since we set ControllerReference, K8s will handle the deletion of the namespace.
-	// If we need custom cleanup before the namespace is gone, we'd do it here.
-
-	controllerutil.RemoveFinalizer(canvas, v1alpha1.FinalizerCanvas)
-	if err := r.Update(ctx, canvas); err != nil {
-		log.Error(err, "Failed to remove finalizer")
-		return ctrl.Result{}, err
+// deleteIfExists deletes obj and reports whether it existed.
+func (r *Reconciler) deleteIfExists(ctx context.Context, obj client.Object) (bool, error) {
+	if err := r.Delete(ctx, obj); err != nil {
+		if errors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
 	}
-
-	return ctrl.Result{}, nil
+	return true, nil
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&v1alpha1.Canvas{}).
-		Owns(&corev1.Namespace{}).
+		// Namespaces are mapped to every canvas spanning them, which covers
+		// the home namespaces the canvases own.
+		Watches(&corev1.Namespace{}, handler.EnqueueRequestsFromMapFunc(r.canvasesForNamespace)).
+		// Canvases are mapped to the canvases allowing traffic from them.
+		Watches(&v1alpha1.Canvas{}, handler.EnqueueRequestsFromMapFunc(r.canvasesAllowingCanvas)).
+		Owns(&rbacv1.RoleBinding{}).
+		Owns(&corev1.ResourceQuota{}).
+		Owns(&corev1.LimitRange{}).
+		Owns(&networkingv1.NetworkPolicy{}).
 		Complete(r)
 }
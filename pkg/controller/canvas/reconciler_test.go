@@ -2,32 +2,61 @@ package canvas
 
 import (
 	"context"
+	"encoding/json"
+	"strings"
 	"testing"
 
 	"github.com/orray-proj/orray/api/v1alpha1"
 	"github.com/orray-proj/orray/pkg/logging"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/events"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
+// recordedEvents drains the events emitted to recorder.
+func recordedEvents(recorder *events.FakeRecorder) []string {
+	var recorded []string
+	for {
+		select {
+		case event := <-recorder.Events:
+			recorded = append(recorded, event)
+		default:
+			return recorded
+		}
+	}
+}
+
 func TestReconcile(t *testing.T) {
 	scheme := runtime.NewScheme()
 	require.NoError(t, v1alpha1.AddToScheme(scheme))
 	require.NoError(t, corev1.AddToScheme(scheme))
+	require.NoError(t, rbacv1.AddToScheme(scheme))
+	require.NoError(t, networkingv1.AddToScheme(scheme))
 
 	logger, _ := logging.NewLogger(logging.DebugLevel, logging.ConsoleFormat)
 
 	t.Run("CanvasNotFound", func(t *testing.T) {
 		cl := fake.NewClientBuilder().WithScheme(scheme).Build()
-		r := &Reconciler{Client: cl, Logger: logger}
+		r := &Reconciler{Client: cl, Logger: logger, Recorder: events.NewFakeRecorder(100)}
 
 		req := ctrl.Request{
 			NamespacedName: types.NamespacedName{
@@ -55,7 +84,8 @@ func TestReconcile(t *testing.T) {
 			WithRuntimeObjects(canvas).
 			WithStatusSubresource(canvas).
 			Build()
-		r := &Reconciler{Client: cl, Logger: logger}
+		recorder := events.NewFakeRecorder(100)
+		r := &Reconciler{Client: cl, Logger: logger, Recorder: recorder}
 
 		req := ctrl.Request{
 			NamespacedName: types.NamespacedName{
@@ -88,9 +118,15 @@ func TestReconcile(t *testing.T) {
 		ns := &corev1.Namespace{}
 		err = cl.Get(context.Background(), types.NamespacedName{Name: "test-canvas"}, ns)
 		assert.NoError(t, err)
-		assert.Equal(t, "true", ns.Annotations[v1alpha1.AnnotationCanvas])
+		assert.Equal(t, "test-canvas", ns.Annotations[v1alpha1.AnnotationCanvas])
 		assert.Equal(t, v1alpha1.ManagedByValue, ns.Annotations[v1alpha1.AnnotationManagedBy])
 
+		assert.Equal(t, []string{
+			"Normal FinalizerAdded Added finalizer " + v1alpha1.FinalizerCanvas,
+			"Normal NamespaceCreated Created namespace test-canvas",
+			"Normal Provisioned Canvas provisioned successfully",
+		}, recordedEvents(recorder))
+
 		// Check OwnerReference
 		assert.Len(t, ns.OwnerReferences, 1)
 		assert.Equal(t, updatedCanvas.Name, ns.OwnerReferences[0].Name)
@@ -103,7 +139,8 @@ func TestReconcile(t *testing.T) {
 				Finalizers: []string{v1alpha1.FinalizerCanvas},
 			},
 			Spec: v1alpha1.CanvasSpec{
-				DisplayName: "Test Canvas",
+				DisplayName:    "Test Canvas",
+				AdoptionPolicy: v1alpha1.AdoptionPolicyAdopt,
 			},
 			Status: v1alpha1.CanvasStatus{
 				Conditions: []metav1.Condition{
@@ -130,7 +167,8 @@ func TestReconcile(t *testing.T) {
 			WithRuntimeObjects(canvas, ns).
 			WithStatusSubresource(canvas).
 			Build()
-		r := &Reconciler{Client: cl, Logger: logger}
+		recorder := events.NewFakeRecorder(100)
+		r := &Reconciler{Client: cl, Logger: logger, Recorder: recorder}
 
 		req := ctrl.Request{
 			NamespacedName: types.NamespacedName{
@@ -146,8 +184,562 @@ func TestReconcile(t *testing.T) {
 		updatedNS := &corev1.Namespace{}
 		err = cl.Get(context.Background(), types.NamespacedName{Name: "test-canvas"}, updatedNS)
 		assert.NoError(t, err)
-		assert.Equal(t, "true", updatedNS.Annotations[v1alpha1.AnnotationCanvas])
+		assert.Equal(t, "test-canvas", updatedNS.Annotations[v1alpha1.AnnotationCanvas])
 		assert.Equal(t, v1alpha1.ManagedByValue, updatedNS.Annotations[v1alpha1.AnnotationManagedBy])
+		assert.Contains(t, recordedEvents(recorder), "Normal NamespaceAdopted Adopted existing namespace test-canvas (Adopt)")
+	})
+
+	t.Run("Members", func(t *testing.T) {
+		canvas := &v1alpha1.Canvas{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       "test-canvas",
+				Finalizers: []string{v1alpha1.FinalizerCanvas},
+			},
+			Spec: v1alpha1.CanvasSpec{
+				DisplayName: "Test Canvas",
+				Members: []v1alpha1.CanvasMember{
+					{Kind: v1alpha1.MemberKindUser, Name: "alice", Role: v1alpha1.CanvasRoleViewer},
+					{Kind: v1alpha1.MemberKindGroup, Name: "admins", Role: v1alpha1.CanvasRoleOwner},
+					{Kind: v1alpha1.MemberKindServiceAccount, Name: "ci", Role: v1alpha1.CanvasRoleOwner},
+				},
+			},
+		}
+
+		cl := fake.NewClientBuilder().
+			WithScheme(scheme).
+			WithRuntimeObjects(canvas).
+			WithStatusSubresource(canvas).
+			Build()
+		r := &Reconciler{Client: cl, Logger: logger, Recorder: events.NewFakeRecorder(100)}
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "test-canvas"}}
+
+		_, err := r.Reconcile(context.Background(), req)
+		require.NoError(t, err)
+
+		viewers := &rbacv1.RoleBinding{}
+		require.NoError(t, cl.Get(context.Background(),
+			types.NamespacedName{Namespace: "test-canvas", Name: "orray-viewer"}, viewers))
+		assert.Equal(t, rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "ClusterRole",
+			Name:     "orray-canvas-viewer",
+		}, viewers.RoleRef)
+		assert.Equal(t, v1alpha1.ManagedByValue, viewers.Annotations[v1alpha1.AnnotationManagedBy])
+		require.Len(t, viewers.OwnerReferences, 1)
+		assert.Equal(t, "test-canvas", viewers.OwnerReferences[0].Name)
+		assert.Equal(t, []rbacv1.Subject{
+			{Kind: rbacv1.UserKind, APIGroup: rbacv1.GroupName, Name: "alice"},
+		}, viewers.Subjects)
+
+		owners := &rbacv1.RoleBinding{}
+		require.NoError(t, cl.Get(context.Background(),
+			types.NamespacedName{Namespace: "test-canvas", Name: "orray-owner"}, owners))
+		assert.Equal(t, []rbacv1.Subject{
+			{Kind: rbacv1.GroupKind, APIGroup: rbacv1.GroupName, Name: "admins"},
+			{Kind: rbacv1.ServiceAccountKind, Name: "ci", Namespace: "test-canvas"},
+		}, owners.Subjects)
+
+		err = cl.Get(context.Background(),
+			types.NamespacedName{Namespace: "test-canvas", Name: "orray-editor"}, &rbacv1.RoleBinding{})
+		assert.True(t, errors.IsNotFound(err))
+
+		updatedCanvas := &v1alpha1.Canvas{}
+		require.NoError(t, cl.Get(context.Background(), req.NamespacedName, updatedCanvas))
+		rbacCond := meta.FindStatusCondition(updatedCanvas.Status.Conditions, v1alpha1.ConditionTypeRBACReady)
+		require.NotNil(t, rbacCond)
+		assert.Equal(t, metav1.ConditionTrue, rbacCond.Status)
+		assert.Equal(t, v1alpha1.ReasonSynced, rbacCond.Reason)
+
+		// Alice becomes an editor.
+		updatedCanvas.Spec.Members[0].Role = v1alpha1.CanvasRoleEditor
+		require.NoError(t, cl.Update(context.Background(), updatedCanvas))
+		_, err = r.Reconcile(context.Background(), req)
+		require.NoError(t, err)
+
+		err = cl.Get(context.Background(),
+			types.NamespacedName{Namespace: "test-canvas", Name: "orray-viewer"}, &rbacv1.RoleBinding{})
+		assert.True(t, errors.IsNotFound(err))
+		editors := &rbacv1.RoleBinding{}
+		require.NoError(t, cl.Get(context.Background(),
+			types.NamespacedName{Namespace: "test-canvas", Name: "orray-editor"}, editors))
+		assert.Equal(t, "alice", editors.Subjects[0].Name)
+	})
+
+	t.Run("RBACFailure", func(t *testing.T) {
+		canvas := &v1alpha1.Canvas{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       "test-canvas",
+				Finalizers: []string{v1alpha1.FinalizerCanvas},
+			},
+			Spec: v1alpha1.CanvasSpec{
+				DisplayName: "Test Canvas",
+				Members: []v1alpha1.CanvasMember{
+					{Kind: v1alpha1.MemberKindUser, Name: "alice", Role: v1alpha1.CanvasRoleViewer},
+				},
+			},
+		}
+
+		cl := fake.NewClientBuilder().
+			WithScheme(scheme).
+			WithRuntimeObjects(canvas).
+			WithStatusSubresource(canvas).
+			WithInterceptorFuncs(interceptor.Funcs{
+				Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+					if _, ok := obj.(*rbacv1.RoleBinding); ok {
+						return errors.NewForbidden(rbacv1.Resource("rolebindings"), obj.GetName(), nil)
+					}
+					return c.Create(ctx, obj, opts...)
+				},
+			}).
+			Build()
+		recorder := events.NewFakeRecorder(100)
+		r := &Reconciler{Client: cl, Logger: logger, Recorder: recorder}
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "test-canvas"}}
+
+		_, err := r.Reconcile(context.Background(), req)
+		assert.Error(t, err)
+
+		updatedCanvas := &v1alpha1.Canvas{}
+		require.NoError(t, cl.Get(context.Background(), req.NamespacedName, updatedCanvas))
+		rbacCond := meta.FindStatusCondition(updatedCanvas.Status.Conditions, v1alpha1.ConditionTypeRBACReady)
+		require.NotNil(t, rbacCond)
+		assert.Equal(t, metav1.ConditionFalse, rbacCond.Status)
+		assert.Equal(t, v1alpha1.ReasonFailed, rbacCond.Reason)
+		assert.Contains(t, rbacCond.Message, "orray-viewer")
+		readyCond := meta.FindStatusCondition(updatedCanvas.Status.Conditions, v1alpha1.ConditionTypeReady)
+		require.NotNil(t, readyCond)
+		assert.Equal(t, metav1.ConditionFalse, readyCond.Status)
+
+		recorded := recordedEvents(recorder)
+		require.NotEmpty(t, recorded)
+		assert.True(t, strings.HasPrefix(recorded[len(recorded)-1], "Warning RBACFailed Failed to sync RBAC: "))
+	})
+
+	t.Run("Resources", func(t *testing.T) {
+		canvas := &v1alpha1.Canvas{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       "test-canvas",
+				Finalizers: []string{v1alpha1.FinalizerCanvas},
+			},
+			Spec: v1alpha1.CanvasSpec{
+				DisplayName: "Test Canvas",
+				Resources: &v1alpha1.CanvasResources{
+					Quota: corev1.ResourceList{
+						corev1.ResourceRequestsCPU: resource.MustParse("2"),
+						corev1.ResourcePods:        resource.MustParse("10"),
+					},
+					DefaultRequests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m")},
+					DefaultLimits:   corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m")},
+				},
+			},
+		}
+
+		cl := fake.NewClientBuilder().
+			WithScheme(scheme).
+			WithRuntimeObjects(canvas).
+			WithStatusSubresource(canvas).
+			Build()
+		r := &Reconciler{Client: cl, Logger: logger, Recorder: events.NewFakeRecorder(100)}
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "test-canvas"}}
+
+		_, err := r.Reconcile(context.Background(), req)
+		require.NoError(t, err)
+
+		quota := &corev1.ResourceQuota{}
+		quotaKey := types.NamespacedName{Namespace: "test-canvas", Name: "orray-quota"}
+		require.NoError(t, cl.Get(context.Background(), quotaKey, quota))
+		assert.Equal(t, canvas.Spec.Resources.Quota, quota.Spec.Hard)
+		require.Len(t, quota.OwnerReferences, 1)
+
+		limitRange := &corev1.LimitRange{}
+		limitRangeKey := types.NamespacedName{Namespace: "test-canvas", Name: "orray-limits"}
+		require.NoError(t, cl.Get(context.Background(), limitRangeKey, limitRange))
+		require.Len(t, limitRange.Spec.Limits, 1)
+		assert.Equal(t, corev1.LimitTypeContainer, limitRange.Spec.Limits[0].Type)
+		assert.Equal(t, canvas.Spec.Resources.DefaultRequests, limitRange.Spec.Limits[0].DefaultRequest)
+		assert.Equal(t, canvas.Spec.Resources.DefaultLimits, limitRange.Spec.Limits[0].Default)
+
+		// The quota controller reports the usage of the namespace.
+		quota.Status = corev1.ResourceQuotaStatus{
+			Hard: quota.Spec.Hard,
+			Used: corev1.ResourceList{
+				corev1.ResourceRequestsCPU: resource.MustParse("500m"),
+				corev1.ResourcePods:        resource.MustParse("3"),
+			},
+		}
+		require.NoError(t, cl.Update(context.Background(), quota))
+		_, err = r.Reconcile(context.Background(), req)
+		require.NoError(t, err)
+
+		updatedCanvas := &v1alpha1.Canvas{}
+		require.NoError(t, cl.Get(context.Background(), req.NamespacedName, updatedCanvas))
+		require.NotNil(t, updatedCanvas.Status.Quota)
+		assert.Equal(t, quota.Status.Hard, updatedCanvas.Status.Quota.Hard)
+		assert.Equal(t, quota.Status.Used, updatedCanvas.Status.Quota.Used)
+
+		// Without resources, the quota and limits are removed.
+		updatedCanvas.Spec.Resources = nil
+		require.NoError(t, cl.Update(context.Background(), updatedCanvas))
+		_, err = r.Reconcile(context.Background(), req)
+		require.NoError(t, err)
+
+		assert.True(t, errors.IsNotFound(cl.Get(context.Background(), quotaKey, &corev1.ResourceQuota{})))
+		assert.True(t, errors.IsNotFound(cl.Get(context.Background(), limitRangeKey, &corev1.LimitRange{})))
+		require.NoError(t, cl.Get(context.Background(), req.NamespacedName, updatedCanvas))
+		assert.Nil(t, updatedCanvas.Status.Quota)
+	})
+
+	t.Run("NetworkIsolation", func(t *testing.T) {
+		canvas := &v1alpha1.Canvas{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       "test-canvas",
+				Finalizers: []string{v1alpha1.FinalizerCanvas},
+			},
+			Spec: v1alpha1.CanvasSpec{DisplayName: "Test Canvas"},
+		}
+		frontend := &v1alpha1.Canvas{
+			ObjectMeta: metav1.ObjectMeta{Name: "frontend"},
+			Spec:       v1alpha1.CanvasSpec{DisplayName: "Frontend", HomeNamespace: "web"},
+		}
+
+		cl := fake.NewClientBuilder().
+			WithScheme(scheme).
+			WithRuntimeObjects(canvas, frontend).
+			WithStatusSubresource(canvas).
+			Build()
+		r := &Reconciler{Client: cl, Logger: logger, Recorder: events.NewFakeRecorder(100)}
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "test-canvas"}}
+		policyKey := types.NamespacedName{Namespace: "test-canvas", Name: "orray-isolation"}
+
+		// reconcileWith applies network to the canvas and reconciles it.
+		reconcileWith := func(t *testing.T, network *v1alpha1.CanvasNetwork) *metav1.Condition {
+			current := &v1alpha1.Canvas{}
+			require.NoError(t, cl.Get(context.Background(), req.NamespacedName, current))
+			current.Spec.Network = network
+			require.NoError(t, cl.Update(context.Background(), current))
+
+			_, err := r.Reconcile(context.Background(), req)
+			require.NoError(t, err)
+
+			require.NoError(t, cl.Get(context.Background(), req.NamespacedName, current))
+			cond := meta.FindStatusCondition(current.Status.Conditions, v1alpha1.ConditionTypeNetworkIsolated)
+			require.NotNil(t, cond)
+			return cond
+		}
+
+		t.Run("Open", func(t *testing.T) {
+			cond := reconcileWith(t, nil)
+			assert.Equal(t, metav1.ConditionFalse, cond.Status)
+			assert.Equal(t, v1alpha1.ReasonIsolationDisabled, cond.Reason)
+			err := cl.Get(context.Background(), policyKey, &networkingv1.NetworkPolicy{})
+			assert.True(t, errors.IsNotFound(err))
+		})
+
+		t.Run("NamespaceIsolated", func(t *testing.T) {
+			cond := reconcileWith(t, &v1alpha1.CanvasNetwork{
+				Isolation:         v1alpha1.NetworkIsolationNamespaceIsolated,
+				AllowedCanvases:   []string{"frontend", "missing"},
+				AllowedNamespaces: []string{"ingress-nginx"},
+			})
+			assert.Equal(t, metav1.ConditionTrue, cond.Status)
+
+			policy := &networkingv1.NetworkPolicy{}
+			require.NoError(t, cl.Get(context.Background(), policyKey, policy))
+			assert.Equal(t, metav1.LabelSelector{}, policy.Spec.PodSelector)
+			assert.Equal(t, []networkingv1.PolicyType{networkingv1.PolicyTypeIngress}, policy.Spec.PolicyTypes)
+			require.Len(t, policy.Spec.Ingress, 1)
+			assert.Equal(t, []networkingv1.NetworkPolicyPeer{
+				{PodSelector: &metav1.LabelSelector{}},
+				{NamespaceSelector: &metav1.LabelSelector{
+					MatchExpressions: []metav1.LabelSelectorRequirement{{
+						Key:      corev1.LabelMetadataName,
+						Operator: metav1.LabelSelectorOpIn,
+						Values:   []string{"ingress-nginx", "web"},
+					}},
+				}},
+			}, policy.Spec.Ingress[0].From)
+
+			// The canvas follows the canvases it allows.
+			assert.Equal(t, []reconcile.Request{req}, r.canvasesAllowingCanvas(context.Background(), frontend))
+			assert.Empty(t, r.canvasesAllowingCanvas(context.Background(), canvas))
+		})
+
+		t.Run("DenyAll", func(t *testing.T) {
+			cond := reconcileWith(t, &v1alpha1.CanvasNetwork{Isolation: v1alpha1.NetworkIsolationDenyAll})
+			assert.Equal(t, metav1.ConditionTrue, cond.Status)
+
+			policy := &networkingv1.NetworkPolicy{}
+			require.NoError(t, cl.Get(context.Background(), policyKey, policy))
+			assert.Empty(t, policy.Spec.Ingress)
+		})
+
+		t.Run("BackToOpen", func(t *testing.T) {
+			cond := reconcileWith(t, &v1alpha1.CanvasNetwork{Isolation: v1alpha1.NetworkIsolationOpen})
+			assert.Equal(t, metav1.ConditionFalse, cond.Status)
+			err := cl.Get(context.Background(), policyKey, &networkingv1.NetworkPolicy{})
+			assert.True(t, errors.IsNotFound(err))
+		})
+	})
+
+	t.Run("Adoption", func(t *testing.T) {
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "test-canvas"}}
+
+		// setup returns a client holding a canvas with policy and a
+		// namespace of the same name created before it.
+		setup := func(policy v1alpha1.AdoptionPolicy, owners ...metav1.OwnerReference) client.Client {
+			canvas := &v1alpha1.Canvas{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:       "test-canvas",
+					UID:        "canvas-uid",
+					Finalizers: []string{v1alpha1.FinalizerCanvas},
+				},
+				Spec: v1alpha1.CanvasSpec{DisplayName: "Test Canvas", AdoptionPolicy: policy},
+			}
+			ns := &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-canvas", OwnerReferences: owners},
+			}
+			return fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithRuntimeObjects(canvas, ns).
+				WithStatusSubresource(canvas).
+				Build()
+		}
+
+		namespaceCondition := func(t *testing.T, cl client.Client) *metav1.Condition {
+			canvas := &v1alpha1.Canvas{}
+			require.NoError(t, cl.Get(context.Background(), req.NamespacedName, canvas))
+			cond := meta.FindStatusCondition(canvas.Status.Conditions, v1alpha1.ConditionTypeNamespaceReady)
+			require.NotNil(t, cond)
+			return cond
+		}
+
+		t.Run("Refuse", func(t *testing.T) {
+			cl := setup(v1alpha1.AdoptionPolicyRefuse)
+			recorder := events.NewFakeRecorder(100)
+			r := &Reconciler{Client: cl, Logger: logger, Recorder: recorder}
+
+			_, err := r.Reconcile(context.Background(), req)
+			assert.Error(t, err)
+
+			cond := namespaceCondition(t, cl)
+			assert.Equal(t, metav1.ConditionFalse, cond.Status)
+			assert.Equal(t, v1alpha1.ReasonNamespaceConflict, cond.Reason)
+
+			ns := &corev1.Namespace{}
+			require.NoError(t, cl.Get(context.Background(), client.ObjectKey{Name: "test-canvas"}, ns))
+			assert.NotContains(t, ns.Annotations, v1alpha1.AnnotationCanvas)
+			assert.Contains(t, recordedEvents(recorder), "Warning NamespaceConflict Failed to sync namespace: "+
+				"namespace test-canvas already exists and the adoption policy is Refuse")
+		})
+
+		t.Run("ForeignController", func(t *testing.T) {
+			isController := true
+			cl := setup(v1alpha1.AdoptionPolicyAdoptAndOwn, metav1.OwnerReference{
+				APIVersion: "example.com/v1",
+				Kind:       "Tenant",
+				Name:       "other",
+				UID:        "other-uid",
+				Controller: &isController,
+			})
+			r := &Reconciler{Client: cl, Logger: logger, Recorder: events.NewFakeRecorder(100)}
+
+			_, err := r.Reconcile(context.Background(), req)
+			assert.Error(t, err)
+
+			cond := namespaceCondition(t, cl)
+			assert.Equal(t, v1alpha1.ReasonNamespaceConflict, cond.Reason)
+			assert.Contains(t, cond.Message, "Tenant other")
+		})
+
+		t.Run("Adopt", func(t *testing.T) {
+			cl := setup(v1alpha1.AdoptionPolicyAdopt)
+			r := &Reconciler{Client: cl, Logger: logger, Recorder: events.NewFakeRecorder(100)}
+
+			_, err := r.Reconcile(context.Background(), req)
+			require.NoError(t, err)
+
+			cond := namespaceCondition(t, cl)
+			assert.Equal(t, metav1.ConditionTrue, cond.Status)
+			assert.Equal(t, v1alpha1.ReasonAdopted, cond.Reason)
+
+			ns := &corev1.Namespace{}
+			require.NoError(t, cl.Get(context.Background(), client.ObjectKey{Name: "test-canvas"}, ns))
+			assert.Equal(t, "true", ns.Annotations[v1alpha1.AnnotationAdopted])
+			assert.Equal(t, "test-canvas", ns.Annotations[v1alpha1.AnnotationCanvas])
+			assert.Empty(t, ns.OwnerReferences)
+
+			// Members are not granted access to namespaces the canvas did not
+			// create.
+			canvas := &v1alpha1.Canvas{}
+			require.NoError(t, cl.Get(context.Background(), req.NamespacedName, canvas))
+			rbacCond := meta.FindStatusCondition(canvas.Status.Conditions, v1alpha1.ConditionTypeRBACReady)
+			require.NotNil(t, rbacCond)
+			assert.Equal(t, metav1.ConditionFalse, rbacCond.Status)
+			assert.Equal(t, v1alpha1.ReasonNamespaceNotOwned, rbacCond.Reason)
+
+			// Deleting the canvas releases the namespace.
+			require.NoError(t, cl.Get(context.Background(), req.NamespacedName, canvas))
+			require.NoError(t, cl.Delete(context.Background(), canvas))
+			_, err = r.Reconcile(context.Background(), req)
+			require.NoError(t, err)
+
+			require.NoError(t, cl.Get(context.Background(), client.ObjectKey{Name: "test-canvas"}, ns))
+			assert.NotContains(t, ns.Annotations, v1alpha1.AnnotationAdopted)
+			assert.NotContains(t, ns.Annotations, v1alpha1.AnnotationCanvas)
+			assert.NotContains(t, ns.Annotations, v1alpha1.AnnotationManagedBy)
+		})
+
+		t.Run("AdoptAndOwn", func(t *testing.T) {
+			cl := setup(v1alpha1.AdoptionPolicyAdoptAndOwn)
+			r := &Reconciler{Client: cl, Logger: logger, Recorder: events.NewFakeRecorder(100)}
+
+			_, err := r.Reconcile(context.Background(), req)
+			require.NoError(t, err)
+
+			ns := &corev1.Namespace{}
+			require.NoError(t, cl.Get(context.Background(), client.ObjectKey{Name: "test-canvas"}, ns))
+			assert.Equal(t, "true", ns.Annotations[v1alpha1.AnnotationAdopted])
+			owner := metav1.GetControllerOf(ns)
+			require.NotNil(t, owner)
+			assert.Equal(t, "Canvas", owner.Kind)
+			assert.Equal(t, "test-canvas", owner.Name)
+
+			// Switching back to Adopt gives up the ownership.
+			canvas := &v1alpha1.Canvas{}
+			require.NoError(t, cl.Get(context.Background(), req.NamespacedName, canvas))
+			canvas.Spec.AdoptionPolicy = v1alpha1.AdoptionPolicyAdopt
+			require.NoError(t, cl.Update(context.Background(), canvas))
+			_, err = r.Reconcile(context.Background(), req)
+			require.NoError(t, err)
+
+			require.NoError(t, cl.Get(context.Background(), client.ObjectKey{Name: "test-canvas"}, ns))
+			assert.Nil(t, metav1.GetControllerOf(ns))
+			assert.Equal(t, "true", ns.Annotations[v1alpha1.AnnotationAdopted])
+		})
+
+		t.Run("AdoptedByOtherCanvas", func(t *testing.T) {
+			newCanvas := func(name string) *v1alpha1.Canvas {
+				return &v1alpha1.Canvas{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:       name,
+						UID:        types.UID(name + "-uid"),
+						Finalizers: []string{v1alpha1.FinalizerCanvas},
+					},
+					Spec: v1alpha1.CanvasSpec{
+						DisplayName:    name,
+						HomeNamespace:  "shared",
+						AdoptionPolicy: v1alpha1.AdoptionPolicyAdopt,
+					},
+				}
+			}
+			first, second := newCanvas("first"), newCanvas("second")
+			cl := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithRuntimeObjects(first, second, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "shared"}}).
+				WithStatusSubresource(first, second).
+				Build()
+			recorder := events.NewFakeRecorder(100)
+			r := &Reconciler{Client: cl, Logger: logger, Recorder: recorder}
+
+			firstReq := ctrl.Request{NamespacedName: types.NamespacedName{Name: "first"}}
+			secondReq := ctrl.Request{NamespacedName: types.NamespacedName{Name: "second"}}
+			_, err := r.Reconcile(context.Background(), firstReq)
+			require.NoError(t, err)
+			recordedEvents(recorder)
+
+			_, err = r.Reconcile(context.Background(), secondReq)
+			assert.Error(t, err)
+
+			canvas := &v1alpha1.Canvas{}
+			require.NoError(t, cl.Get(context.Background(), secondReq.NamespacedName, canvas))
+			cond := meta.FindStatusCondition(canvas.Status.Conditions, v1alpha1.ConditionTypeNamespaceReady)
+			require.NotNil(t, cond)
+			assert.Equal(t, metav1.ConditionFalse, cond.Status)
+			assert.Equal(t, v1alpha1.ReasonNamespaceConflict, cond.Reason)
+			assert.Contains(t, recordedEvents(recorder), "Warning NamespaceConflict Failed to sync namespace: "+
+				"namespace shared already belongs to canvas first")
+
+			ns := &corev1.Namespace{}
+			require.NoError(t, cl.Get(context.Background(), client.ObjectKey{Name: "shared"}, ns))
+			assert.Equal(t, "first", ns.Annotations[v1alpha1.AnnotationCanvas])
+
+			// Deleting the second canvas leaves the marks of the first one.
+			require.NoError(t, cl.Delete(context.Background(), canvas))
+			_, err = r.Reconcile(context.Background(), secondReq)
+			require.NoError(t, err)
+
+			require.NoError(t, cl.Get(context.Background(), client.ObjectKey{Name: "shared"}, ns))
+			assert.Equal(t, "first", ns.Annotations[v1alpha1.AnnotationCanvas])
+			assert.Equal(t, "true", ns.Annotations[v1alpha1.AnnotationAdopted])
+		})
+	})
+
+	t.Run("Namespaces", func(t *testing.T) {
+		canvas := &v1alpha1.Canvas{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       "test-canvas",
+				Finalizers: []string{v1alpha1.FinalizerCanvas},
+			},
+			Spec: v1alpha1.CanvasSpec{
+				DisplayName:   "Test Canvas",
+				HomeNamespace: "payments",
+				Namespaces: []v1alpha1.CanvasNamespace{
+					{Name: "ingress"},
+					{Name: "missing"},
+					{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "payments"}}},
+				},
+				Network: &v1alpha1.CanvasNetwork{Isolation: v1alpha1.NetworkIsolationNamespaceIsolated},
+			},
+		}
+		namespace := func(name string, labels map[string]string) *corev1.Namespace {
+			return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+		}
+
+		cl := fake.NewClientBuilder().
+			WithScheme(scheme).
+			WithRuntimeObjects(
+				canvas,
+				namespace("ingress", nil),
+				namespace("workers", map[string]string{"app": "payments"}),
+				namespace("other", map[string]string{"app": "billing"}),
+			).
+			WithStatusSubresource(canvas).
+			Build()
+		r := &Reconciler{Client: cl, Logger: logger, Recorder: events.NewFakeRecorder(100)}
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "test-canvas"}}
+
+		_, err := r.Reconcile(context.Background(), req)
+		require.NoError(t, err)
+
+		updatedCanvas := &v1alpha1.Canvas{}
+		require.NoError(t, cl.Get(context.Background(), req.NamespacedName, updatedCanvas))
+		assert.Equal(t, []string{"ingress", "payments", "workers"}, updatedCanvas.Status.Namespaces)
+
+		// Managed objects live in the home namespace.
+		home := &corev1.Namespace{}
+		require.NoError(t, cl.Get(context.Background(), client.ObjectKey{Name: "payments"}, home))
+		assert.Equal(t, "test-canvas", home.Annotations[v1alpha1.AnnotationCanvas])
+		err = cl.Get(context.Background(), client.ObjectKey{Name: "test-canvas"}, &corev1.Namespace{})
+		assert.True(t, errors.IsNotFound(err))
+		rbacCond := meta.FindStatusCondition(updatedCanvas.Status.Conditions, v1alpha1.ConditionTypeRBACReady)
+		require.NotNil(t, rbacCond)
+		assert.Equal(t, metav1.ConditionTrue, rbacCond.Status)
+
+		// The workloads of the canvas may reach each other.
+		policy := &networkingv1.NetworkPolicy{}
+		require.NoError(t, cl.Get(context.Background(),
+			types.NamespacedName{Namespace: "payments", Name: "orray-isolation"}, policy))
+		require.Len(t, policy.Spec.Ingress, 1)
+		require.Len(t, policy.Spec.Ingress[0].From, 2)
+		assert.Equal(t, []string{"ingress", "workers"},
+			policy.Spec.Ingress[0].From[1].NamespaceSelector.MatchExpressions[0].Values)
+
+		// New matching namespaces trigger a reconciliation of the canvas.
+		requests := r.canvasesForNamespace(context.Background(),
+			namespace("workers-2", map[string]string{"app": "payments"}))
+		assert.Equal(t, []reconcile.Request{req}, requests)
+		assert.Empty(t, r.canvasesForNamespace(context.Background(),
+			namespace("other-2", map[string]string{"app": "billing"})))
 	})
 
 	t.Run("DeleteCanvas", func(t *testing.T) {
@@ -163,8 +755,10 @@ func TestReconcile(t *testing.T) {
 		cl := fake.NewClientBuilder().
 			WithScheme(scheme).
 			WithRuntimeObjects(canvas).
+			WithStatusSubresource(canvas).
 			Build()
-		r := &Reconciler{Client: cl, Logger: logger}
+		recorder := events.NewFakeRecorder(100)
+		r := &Reconciler{Client: cl, Logger: logger, Recorder: recorder}
 
 		req := ctrl.Request{
 			NamespacedName: types.NamespacedName{
@@ -181,5 +775,180 @@ func TestReconcile(t *testing.T) {
 		err = cl.Get(context.Background(), req.NamespacedName, updatedCanvas)
 		assert.Error(t, err)
 		assert.True(t, errors.IsNotFound(err))
+		assert.Contains(t, recordedEvents(recorder), "Normal FinalizerRemoved Removed finalizer "+v1alpha1.FinalizerCanvas)
+	})
+
+	t.Run("DeletionPolicies", func(t *testing.T) {
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "test-canvas"}}
+		roleKey := types.NamespacedName{Namespace: "test-canvas", Name: "orray-viewer"}
+
+		// provision returns a client holding a provisioned canvas with policy,
+		// whose deletion has been requested.
+		provision := func(
+			t *testing.T, policy v1alpha1.DeletionPolicy, annotations map[string]string, objs ...client.Object,
+		) (client.Client, *Reconciler) {
+			canvas := &v1alpha1.Canvas{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "test-canvas",
+					UID:         "canvas-uid",
+					Annotations: annotations,
+					Finalizers:  []string{v1alpha1.FinalizerCanvas},
+				},
+				Spec: v1alpha1.CanvasSpec{
+					DisplayName:    "Test Canvas",
+					DeletionPolicy: policy,
+					Members: []v1alpha1.CanvasMember{
+						{Kind: v1alpha1.MemberKindUser, Name: "alice", Role: v1alpha1.CanvasRoleViewer},
+					},
+				},
+			}
+			cl := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(append(objs, canvas)...).
+				WithStatusSubresource(canvas).
+				WithIndex(&corev1.Pod{}, "status.phase", func(obj client.Object) []string {
+					return []string{string(obj.(*corev1.Pod).Status.Phase)}
+				}).
+				Build()
+			r := &Reconciler{Client: cl, Logger: logger, Recorder: events.NewFakeRecorder(100), APIReader: cl}
+
+			_, err := r.Reconcile(context.Background(), req)
+			require.NoError(t, err)
+			require.NoError(t, cl.Get(context.Background(), req.NamespacedName, canvas))
+			require.NoError(t, cl.Delete(context.Background(), canvas))
+			return cl, r
+		}
+
+		t.Run("Delete", func(t *testing.T) {
+			cl, r := provision(t, v1alpha1.DeletionPolicyDelete, nil)
+
+			_, err := r.Reconcile(context.Background(), req)
+			require.NoError(t, err)
+
+			err = cl.Get(context.Background(), req.NamespacedName, &v1alpha1.Canvas{})
+			assert.True(t, errors.IsNotFound(err))
+			err = cl.Get(context.Background(), roleKey, &rbacv1.RoleBinding{})
+			assert.True(t, errors.IsNotFound(err))
+
+			// The garbage collector deletes the namespace.
+			ns := &corev1.Namespace{}
+			require.NoError(t, cl.Get(context.Background(), client.ObjectKey{Name: "test-canvas"}, ns))
+			assert.NotNil(t, metav1.GetControllerOf(ns))
+		})
+
+		t.Run("Orphan", func(t *testing.T) {
+			cl, r := provision(t, v1alpha1.DeletionPolicyOrphan, nil)
+
+			_, err := r.Reconcile(context.Background(), req)
+			require.NoError(t, err)
+
+			err = cl.Get(context.Background(), roleKey, &rbacv1.RoleBinding{})
+			assert.True(t, errors.IsNotFound(err))
+			ns := &corev1.Namespace{}
+			require.NoError(t, cl.Get(context.Background(), client.ObjectKey{Name: "test-canvas"}, ns))
+			assert.Empty(t, ns.OwnerReferences)
+			assert.NotContains(t, ns.Annotations, v1alpha1.AnnotationCanvas)
+		})
+
+		t.Run("Retain", func(t *testing.T) {
+			cl, r := provision(t, v1alpha1.DeletionPolicyRetain, nil)
+
+			_, err := r.Reconcile(context.Background(), req)
+			require.NoError(t, err)
+
+			ns := &corev1.Namespace{}
+			require.NoError(t, cl.Get(context.Background(), client.ObjectKey{Name: "test-canvas"}, ns))
+			assert.Empty(t, ns.OwnerReferences)
+
+			cm := &corev1.ConfigMap{}
+			require.NoError(t, cl.Get(context.Background(),
+				types.NamespacedName{Namespace: "test-canvas", Name: "orray-canvas-snapshot"}, cm))
+			assert.Empty(t, cm.OwnerReferences)
+			snapshot := &v1alpha1.Canvas{}
+			require.NoError(t, json.Unmarshal([]byte(cm.Data["canvas.json"]), snapshot))
+			assert.Equal(t, "test-canvas", snapshot.Name)
+			assert.Equal(t, "Test Canvas", snapshot.Spec.DisplayName)
+			assert.Equal(t, v1alpha1.DeletionPolicyRetain, snapshot.Spec.DeletionPolicy)
+		})
+
+		t.Run("Protected", func(t *testing.T) {
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "test-canvas"},
+				Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+			}
+			cl, r := provision(t, v1alpha1.DeletionPolicyDelete,
+				map[string]string{v1alpha1.AnnotationDeletionProtection: "true"}, pod)
+
+			res, err := r.Reconcile(context.Background(), req)
+			require.NoError(t, err)
+			assert.Positive(t, res.RequeueAfter)
+
+			canvas := &v1alpha1.Canvas{}
+			require.NoError(t, cl.Get(context.Background(), req.NamespacedName, canvas))
+			cond := meta.FindStatusCondition(canvas.Status.Conditions, v1alpha1.ConditionTypeTerminating)
+			require.NotNil(t, cond)
+			assert.Equal(t, v1alpha1.ReasonDeletionBlocked, cond.Reason)
+			assert.Contains(t, cond.Message, "pods are running in namespace test-canvas")
+			assert.Contains(t, recordedEvents(r.Recorder.(*events.FakeRecorder)), "Warning DeletionBlocked "+
+				"Deletion is blocked by orray.dev/deletion-protection while pods are running in namespace test-canvas")
+			require.NoError(t, cl.Get(context.Background(), roleKey, &rbacv1.RoleBinding{}))
+
+			// The deletion resumes once the pods are done.
+			pod.Status.Phase = corev1.PodSucceeded
+			require.NoError(t, cl.Status().Update(context.Background(), pod))
+			_, err = r.Reconcile(context.Background(), req)
+			require.NoError(t, err)
+
+			err = cl.Get(context.Background(), req.NamespacedName, &v1alpha1.Canvas{})
+			assert.True(t, errors.IsNotFound(err))
+		})
+
+		t.Run("ProtectedIgnoresSpannedNamespaces", func(t *testing.T) {
+			cl, r := provision(t, v1alpha1.DeletionPolicyDelete,
+				map[string]string{v1alpha1.AnnotationDeletionProtection: "true"},
+				&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "shared"}},
+				&corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shared"},
+					Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+				})
+			canvas := &v1alpha1.Canvas{}
+			require.NoError(t, cl.Get(context.Background(), req.NamespacedName, canvas))
+			canvas.Status.Namespaces = []string{"shared", "test-canvas"}
+			require.NoError(t, cl.Status().Update(context.Background(), canvas))
+
+			_, err := r.Reconcile(context.Background(), req)
+			require.NoError(t, err)
+
+			err = cl.Get(context.Background(), req.NamespacedName, &v1alpha1.Canvas{})
+			assert.True(t, errors.IsNotFound(err))
+		})
+	})
+
+	t.Run("Tracing", func(t *testing.T) {
+		previous := otel.GetTracerProvider()
+		t.Cleanup(func() { otel.SetTracerProvider(previous) })
+		recorder := tracetest.NewSpanRecorder()
+		otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+		cl := fake.NewClientBuilder().
+			WithScheme(scheme).
+			WithInterceptorFuncs(interceptor.Funcs{
+				Get: func(context.Context, client.WithWatch, client.ObjectKey, client.Object, ...client.GetOption) error {
+					return errors.NewServiceUnavailable("unavailable")
+				},
+			}).
+			Build()
+		r := &Reconciler{Client: cl, Logger: logger, Recorder: events.NewFakeRecorder(100)}
+
+		_, err := r.Reconcile(context.Background(), ctrl.Request{
+			NamespacedName: types.NamespacedName{Name: "test-canvas"},
+		})
+		assert.Error(t, err)
+
+		spans := recorder.Ended()
+		require.Len(t, spans, 1)
+		assert.Equal(t, "canvas.Reconcile", spans[0].Name())
+		assert.Contains(t, spans[0].Attributes(), attribute.String("orray.canvas.name", "test-canvas"))
+		assert.Equal(t, codes.Error, spans[0].Status().Code)
 	})
 }
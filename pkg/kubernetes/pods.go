@@ -0,0 +1,25 @@
+package kubernetes
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// HasActivePods reports whether pods are pending or running in namespace.
+// Pods are selected by phase and a single one is fetched, so reader should
+// read from the API server rather than from a cache of every pod.
+func HasActivePods(ctx context.Context, reader client.Reader, namespace string) (bool, error) {
+	for _, phase := range []corev1.PodPhase{corev1.PodPending, corev1.PodRunning} {
+		pods := &corev1.PodList{}
+		if err := reader.List(ctx, pods, client.InNamespace(namespace), client.Limit(1),
+			client.MatchingFields{"status.phase": string(phase)}); err != nil {
+			return false, err
+		}
+		if len(pods.Items) > 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -1,13 +1,16 @@
 package logging
 
 import (
+	"context"
 	"flag"
 	"fmt"
+	"io"
 	"os"
 	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/go-logr/zapr"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 	"k8s.io/klog/v2"
@@ -102,6 +105,12 @@ func NewLoggerFromEnv() (*Logger, error) {
 
 // NewLogger returns a new *Logger with the provided log level.
 func NewLogger(level Level, format Format) (*Logger, error) {
+	return NewLoggerWithWriter(level, format, writer)
+}
+
+// NewLoggerWithWriter returns a new *Logger with the provided log level that
+// writes to w instead of the destination shared by all other loggers.
+func NewLoggerWithWriter(level Level, format Format, w io.Writer) (*Logger, error) {
 	if level < DebugLevel || level > ErrorLevel {
 		return nil, fmt.Errorf("invalid log level: %d", level)
 	}
@@ -126,8 +135,8 @@ func NewLogger(level Level, format Format) (*Logger, error) {
 	}
 	logger, err := cfg.Build(
 		zap.WrapCore(func(core zapcore.Core) zapcore.Core {
-			// Create a new core with our global writer plugged in.
-			return zapcore.NewCore(encoder, writer, core)
+			// Create a new core with the writer plugged in.
+			return zapcore.NewCore(encoder, zapcore.AddSync(w), core)
 		}),
 	)
 	if err != nil {
@@ -151,6 +160,19 @@ func (l *Logger) WithValues(keysAndValues ...any) *Logger {
 	}
 }
 
+// WithContext adds the trace and span IDs of the span carried by ctx, if any,
+// to a logger's context so that log lines can be correlated with traces.
+func (l *Logger) WithContext(ctx context.Context) *Logger {
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.IsValid() {
+		return l
+	}
+	return l.WithValues(
+		"traceId", spanContext.TraceID().String(),
+		"spanId", spanContext.SpanID().String(),
+	)
+}
+
 // Error logs a message at the error level.
 func (l *Logger) Error(err error, msg string, keysAndValues ...any) {
 	l.logger.Errorw(fmt.Sprintf("%s: %v", msg, err), keysAndValues...,
@@ -0,0 +1,214 @@
+package rest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/orray-proj/orray/pkg/logging"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+const (
+	// AuditBodyOmit leaves request bodies out of audit events.
+	AuditBodyOmit = "omit"
+	// AuditBodyRedact records the fields of request bodies, with their values
+	// replaced.
+	AuditBodyRedact = "redact"
+	// AuditBodyInclude records request bodies as they were sent.
+	AuditBodyInclude = "include"
+
+	// auditCanvasContextKey is the gin context key holding the canvas targeted
+	// by requests whose route does not name it, such as creations.
+	auditCanvasContextKey = "auditCanvas"
+	// redactedValue replaces the values of redacted request bodies.
+	redactedValue = "[REDACTED]"
+)
+
+// AuditConfig contains the options for auditing the requests changing
+// canvases.
+type AuditConfig struct {
+	// Enabled controls whether an audit event is logged for every request
+	// that is not a GET or HEAD request.
+	Enabled bool `env:"ENABLED" envDefault:"true"`
+	// BodyPolicy selects how request bodies are recorded, one of omit,
+	// redact or include.
+	BodyPolicy string `env:"BODY_POLICY" envDefault:"omit"`
+	// File, when set, is the path of a file audit events are also written
+	// to as JSON lines. It is rotated once it grows beyond MaxSizeMB, and
+	// rotated files are kept for MaxAgeDays, up to MaxBackups of them.
+	File       string `env:"FILE"`
+	MaxSizeMB  int    `env:"MAX_SIZE_MB" envDefault:"100"`
+	MaxBackups int    `env:"MAX_BACKUPS" envDefault:"10"`
+	MaxAgeDays int    `env:"MAX_AGE_DAYS" envDefault:"30"`
+	// Compress controls whether rotated files are gzipped.
+	Compress bool `env:"COMPRESS" envDefault:"true"`
+}
+
+// auditor writes audit events to the audit stream of the server log and,
+// optionally, to a rotating file.
+type auditor struct {
+	bodyPolicy string
+	loggers    []*logging.Logger
+	file       *lumberjack.Logger
+}
+
+func newAuditor(cfg AuditConfig, logger *logging.Logger) (*auditor, error) {
+	switch cfg.BodyPolicy {
+	case AuditBodyOmit, AuditBodyRedact, AuditBodyInclude:
+	case "":
+		cfg.BodyPolicy = AuditBodyOmit
+	default:
+		return nil, fmt.Errorf("unknown audit body policy %q", cfg.BodyPolicy)
+	}
+
+	a := &auditor{
+		bodyPolicy: cfg.BodyPolicy,
+		loggers:    []*logging.Logger{logger.WithValues("component", "audit")},
+	}
+	if cfg.File != "" {
+		a.file = &lumberjack.Logger{
+			Filename:   cfg.File,
+			MaxSize:    cfg.MaxSizeMB,
+			MaxBackups: cfg.MaxBackups,
+			MaxAge:     cfg.MaxAgeDays,
+			Compress:   cfg.Compress,
+		}
+		fileLogger, err := logging.NewLoggerWithWriter(logging.InfoLevel, logging.JSONFormat, a.file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create audit file logger: %w", err)
+		}
+		a.loggers = append(a.loggers, fileLogger)
+	}
+	return a, nil
+}
+
+// Close closes the audit file, if any.
+func (a *auditor) Close() error {
+	if a.file == nil {
+		return nil
+	}
+	return a.file.Close()
+}
+
+// teeReadCloser copies the request body it reads to a buffer.
+type teeReadCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// audit returns middleware that logs an audit event for every request that
+// may change a canvas, once it has been handled. It must run before
+// authentication so that rejected requests are audited too.
+func (s *Server) audit() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if s.auditor == nil || c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+			c.Next()
+			return
+		}
+
+		start := time.Now()
+		var body *bytes.Buffer
+		if s.auditor.bodyPolicy != AuditBodyOmit && c.Request.Body != nil {
+			// The body is recorded as the handler reads it, so that the
+			// body size limit still applies.
+			body = &bytes.Buffer{}
+			c.Request.Body = &teeReadCloser{
+				Reader: io.TeeReader(c.Request.Body, body),
+				Closer: c.Request.Body,
+			}
+		}
+
+		c.Next()
+
+		s.auditor.log(c, time.Since(start), body)
+	}
+}
+
+// log writes the audit event of a handled request.
+func (a *auditor) log(c *gin.Context, latency time.Duration, body *bytes.Buffer) {
+	route := c.FullPath()
+	if route == "" {
+		route = c.Request.URL.Path
+	}
+	canvas := c.Param("name")
+	if canvas == "" {
+		canvas = c.GetString(auditCanvasContextKey)
+	}
+
+	status := c.Writer.Status()
+	keysAndValues := []any{
+		"requestId", c.GetString("requestId"),
+		"sourceIP", c.ClientIP(),
+		"method", c.Request.Method,
+		"route", route,
+		"canvas", canvas,
+		"status", status,
+		"outcome", auditOutcome(status),
+		"latency", latency,
+	}
+	if user, ok := currentUser(c); ok {
+		keysAndValues = append(keysAndValues, "user", user.Username, "groups", user.Groups)
+	}
+	if code := c.GetString(errorCodeContextKey); code != "" {
+		keysAndValues = append(keysAndValues, "errorCode", code)
+	}
+	if body != nil && body.Len() > 0 {
+		keysAndValues = append(keysAndValues, "body", a.auditBody(body.Bytes()))
+	}
+
+	for _, logger := range a.loggers {
+		logger.WithContext(c.Request.Context()).Info("Audit event", keysAndValues...)
+	}
+}
+
+// auditOutcome classifies the status code of a handled request.
+func auditOutcome(status int) string {
+	switch {
+	case status < http.StatusBadRequest:
+		return "success"
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return "denied"
+	default:
+		return "failure"
+	}
+}
+
+// auditBody returns the form of a request body recorded under the body
+// policy. JSON bodies are decoded so that they nest in JSON logs.
+func (a *auditor) auditBody(data []byte) any {
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		if a.bodyPolicy == AuditBodyRedact {
+			return redactedValue
+		}
+		return string(data)
+	}
+	if a.bodyPolicy == AuditBodyRedact {
+		return redact(value)
+	}
+	return value
+}
+
+// redact replaces the values of a decoded JSON document, keeping the fields
+// of its objects.
+func redact(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, field := range v {
+			v[key] = redact(field)
+		}
+		return v
+	case []any:
+		for i, item := range v {
+			v[i] = redact(item)
+		}
+		return v
+	default:
+		return redactedValue
+	}
+}
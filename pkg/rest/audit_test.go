@@ -0,0 +1,174 @@
+package rest
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/orray-proj/orray/api/v1alpha1"
+	"github.com/orray-proj/orray/pkg/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// auditEvents decodes the audit events among the JSON log lines in data.
+func auditEvents(t *testing.T, data []byte) []map[string]any {
+	var events []map[string]any
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		var line map[string]any
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &line))
+		if line["msg"] == "Audit event" {
+			events = append(events, line)
+		}
+	}
+	return events
+}
+
+func TestAudit(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1alpha1.AddToScheme(scheme))
+
+	newServer := func(t *testing.T, audit AuditConfig) (*Server, *bytes.Buffer) {
+		var logs bytes.Buffer
+		logger, err := logging.NewLoggerWithWriter(logging.InfoLevel, logging.JSONFormat, &logs)
+		require.NoError(t, err)
+		cfg := &Config{
+			Mode:  "test",
+			Audit: audit,
+		}
+		cfg.Auth.Mode = "static"
+		cfg.Auth.StaticTokens = []string{"alice-token=alice|editors|admins"}
+		server, err := NewServer(
+			context.Background(), cfg, logger,
+			fake.NewClientBuilder().WithScheme(scheme).Build(), nil,
+		)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = server.auditor.Close() })
+		return server, &logs
+	}
+
+	send := func(server *Server, method, path, token, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.RemoteAddr = "192.0.2.1:1234"
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Request-ID", "request-1")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		server.router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("MutatingRequests", func(t *testing.T) {
+		server, logs := newServer(t, AuditConfig{Enabled: true})
+
+		w := send(server, http.MethodPost, "/api/v1alpha1/canvases", "alice-token",
+			`{"name":"test","displayName":"Test"}`)
+		require.Equal(t, http.StatusCreated, w.Code)
+		send(server, http.MethodGet, "/api/v1alpha1/canvases/test", "alice-token", "")
+		w = send(server, http.MethodDelete, "/api/v1alpha1/canvases/missing", "alice-token", "")
+		require.Equal(t, http.StatusNotFound, w.Code)
+
+		events := auditEvents(t, logs.Bytes())
+		require.Len(t, events, 2)
+
+		created := events[0]
+		assert.Equal(t, "audit", created["component"])
+		assert.Equal(t, "alice", created["user"])
+		assert.Equal(t, []any{"editors", "admins"}, created["groups"])
+		assert.Equal(t, "192.0.2.1", created["sourceIP"])
+		assert.Equal(t, "request-1", created["requestId"])
+		assert.Equal(t, http.MethodPost, created["method"])
+		assert.Equal(t, "/api/v1alpha1/canvases", created["route"])
+		assert.Equal(t, "test", created["canvas"])
+		assert.EqualValues(t, http.StatusCreated, created["status"])
+		assert.Equal(t, "success", created["outcome"])
+		assert.Contains(t, created, "latency")
+		assert.NotContains(t, created, "body")
+
+		deleted := events[1]
+		assert.Equal(t, "/api/v1alpha1/canvases/:name", deleted["route"])
+		assert.Equal(t, "missing", deleted["canvas"])
+		assert.Equal(t, "failure", deleted["outcome"])
+		assert.Equal(t, "NOT_FOUND", deleted["errorCode"])
+	})
+
+	t.Run("UnauthenticatedRequests", func(t *testing.T) {
+		server, logs := newServer(t, AuditConfig{Enabled: true})
+
+		w := send(server, http.MethodDelete, "/api/v1alpha1/canvases/test", "wrong-token", "")
+		require.Equal(t, http.StatusUnauthorized, w.Code)
+
+		events := auditEvents(t, logs.Bytes())
+		require.Len(t, events, 1)
+		assert.Equal(t, "denied", events[0]["outcome"])
+		assert.NotContains(t, events[0], "user")
+	})
+
+	t.Run("BodyPolicies", func(t *testing.T) {
+		tests := []struct {
+			policy   string
+			expected any
+		}{
+			{policy: AuditBodyInclude, expected: map[string]any{"displayName": "Secret"}},
+			{policy: AuditBodyRedact, expected: map[string]any{"displayName": redactedValue}},
+		}
+		for _, tt := range tests {
+			t.Run(tt.policy, func(t *testing.T) {
+				server, logs := newServer(t, AuditConfig{Enabled: true, BodyPolicy: tt.policy})
+
+				send(server, http.MethodPatch, "/api/v1alpha1/canvases/test", "alice-token", `{"displayName":"Secret"}`)
+
+				events := auditEvents(t, logs.Bytes())
+				require.Len(t, events, 1)
+				assert.Equal(t, tt.expected, events[0]["body"])
+			})
+		}
+	})
+
+	t.Run("File", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "audit", "audit.log")
+		server, logs := newServer(t, AuditConfig{Enabled: true, File: path, MaxSizeMB: 1})
+
+		send(server, http.MethodPut, "/api/v1alpha1/canvases/test", "alice-token", `{"displayName":"Test"}`)
+		require.NoError(t, server.auditor.Close())
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		events := auditEvents(t, data)
+		require.Len(t, events, 1)
+		assert.Equal(t, http.MethodPut, events[0]["method"])
+		assert.Len(t, auditEvents(t, logs.Bytes()), 1)
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		var logs bytes.Buffer
+		logger, err := logging.NewLoggerWithWriter(logging.InfoLevel, logging.JSONFormat, &logs)
+		require.NoError(t, err)
+		server, err := NewServer(
+			context.Background(), &Config{Mode: "test"}, logger,
+			fake.NewClientBuilder().WithScheme(scheme).Build(), nil,
+		)
+		require.NoError(t, err)
+
+		send(server, http.MethodPost, "/api/v1alpha1/canvases", "", `{"name":"test","displayName":"Test"}`)
+		assert.Empty(t, auditEvents(t, logs.Bytes()))
+	})
+
+	t.Run("UnknownBodyPolicy", func(t *testing.T) {
+		logger, _ := logging.NewLogger(logging.InfoLevel, logging.JSONFormat)
+		_, err := newAuditor(AuditConfig{BodyPolicy: "everything"}, logger)
+		assert.Error(t, err)
+	})
+}
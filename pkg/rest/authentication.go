@@ -0,0 +1,59 @@
+package rest
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/orray-proj/orray/pkg/auth"
+	authenticationv1 "k8s.io/api/authentication/v1"
+)
+
+// userContextKey is the gin context key holding the authenticated user.
+const userContextKey = "user"
+
+// authenticate returns middleware that authenticates the bearer token of each
+// request and attaches the user to both the gin and the request context. It
+// lets every request through when authentication is disabled.
+func (s *Server) authenticate() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if s.authenticator == nil {
+			c.Next()
+			return
+		}
+
+		scheme, token, ok := strings.Cut(c.GetHeader("Authorization"), " ")
+		if !ok || !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
+			Unauthorized(c, "")
+			return
+		}
+
+		user, err := s.authenticator.AuthenticateToken(c.Request.Context(), strings.TrimSpace(token))
+		if errors.Is(err, auth.ErrUnauthenticated) {
+			s.requestLogger(c).Debug("Failed to authenticate request", "error", err.Error())
+			Unauthorized(c, "Invalid bearer token")
+			return
+		}
+		if err != nil {
+			// The token could not be checked, which says nothing about its
+			// validity.
+			s.requestLogger(c).Error(err, "Failed to authenticate request")
+			ServiceUnavailable(c, "Authentication is temporarily unavailable")
+			return
+		}
+
+		c.Set(userContextKey, user)
+		c.Request = c.Request.WithContext(auth.WithUser(c.Request.Context(), user))
+		c.Next()
+	}
+}
+
+// currentUser returns the user authenticated for the request, if any.
+func currentUser(c *gin.Context) (*authenticationv1.UserInfo, bool) {
+	value, ok := c.Get(userContextKey)
+	if !ok {
+		return nil, false
+	}
+	user, ok := value.(*authenticationv1.UserInfo)
+	return user, ok
+}
@@ -0,0 +1,98 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/orray-proj/orray/pkg/auth"
+	"github.com/orray-proj/orray/pkg/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	authenticationv1 "k8s.io/api/authentication/v1"
+)
+
+// failingAuthenticator fails to check any token.
+type failingAuthenticator struct{}
+
+func (failingAuthenticator) AuthenticateToken(context.Context, string) (*authenticationv1.UserInfo, error) {
+	return nil, errors.New("failed to review token: connection refused")
+}
+
+func TestAuthenticate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	logger, _ := logging.NewLogger(logging.DebugLevel, logging.ConsoleFormat)
+	authenticator, err := auth.NewStaticAuthenticator([]string{"secret=alice|admins"})
+	require.NoError(t, err)
+	server := &Server{logger: logger, authenticator: authenticator}
+
+	router := gin.New()
+	router.Use(server.authenticate())
+	router.GET("/", func(c *gin.Context) {
+		user, ok := currentUser(c)
+		require.True(t, ok)
+		fromContext, ok := auth.UserFrom(c.Request.Context())
+		require.True(t, ok)
+		assert.Equal(t, user, fromContext)
+		c.String(http.StatusOK, user.Username)
+	})
+
+	tests := []struct {
+		name           string
+		authorization  string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{name: "valid token", authorization: "Bearer secret", expectedStatus: http.StatusOK, expectedBody: "alice"},
+		{name: "lowercase scheme", authorization: "bearer secret", expectedStatus: http.StatusOK, expectedBody: "alice"},
+		{name: "missing header", expectedStatus: http.StatusUnauthorized},
+		{name: "wrong scheme", authorization: "Basic secret", expectedStatus: http.StatusUnauthorized},
+		{name: "invalid token", authorization: "Bearer wrong", expectedStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequestWithContext(context.Background(), http.MethodGet, "/", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusUnauthorized {
+				assert.Contains(t, w.Header().Get("WWW-Authenticate"), "Bearer")
+				assert.Contains(t, w.Body.String(), "UNAUTHORIZED")
+			} else {
+				assert.Equal(t, tt.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestAuthenticateUnavailable(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	logger, _ := logging.NewLogger(logging.DebugLevel, logging.ConsoleFormat)
+	server := &Server{logger: logger, authenticator: failingAuthenticator{}}
+
+	router := gin.New()
+	router.Use(server.authenticate())
+	router.GET("/", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequestWithContext(context.Background(), http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Empty(t, w.Header().Get("WWW-Authenticate"))
+	assert.Contains(t, w.Body.String(), "SERVICE_UNAVAILABLE")
+}
@@ -9,14 +9,26 @@ import (
 
 // @id CreateCanvasV1alpha1
 // @Summary Create a new canvas
-// @Description Create a new canvas with the given display name
+// @Description Create a new canvas with the given display name.
+// @Description Requests sent with an Idempotency-Key can be safely retried: a repeated request gets the response of
+// @Description the first one, marked with the Idempotent-Replayed header.
 // @Tags Canvas
 // @Accept json
 // @Produce json
+// @Param Idempotency-Key header string false "Unique key making retries of the request safe"
 // @Param canvas body dto.CreateCanvasRequest true "Canvas data"
 // @Success 201 {object} dto.Canvas
-// @Failure 400 {object} dto.ErrorResponse "Bad Request"
+// @Header 201 {string} ETag "Resource version of the canvas"
+// @Header 201 {string} Idempotent-Replayed "Set to true when the response is replayed for a repeated Idempotency-Key"
+// @Failure 400 {object} dto.ValidationErrorResponse "Bad Request"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 403 {object} dto.ErrorResponse "Forbidden"
+// @Failure 409 {object} dto.ErrorResponse "Conflict"
+// @Failure 413 {object} dto.ErrorResponse "Request Entity Too Large"
+// @Failure 422 {object} dto.ValidationErrorResponse "Unprocessable Entity"
+// @Failure 429 {object} dto.ErrorResponse "Too Many Requests"
 // @Failure 500 {object} dto.ErrorResponse "Internal Server Error"
+// @Security BearerAuth
 // @Router /v1alpha1/canvases [post]
 func (s *Server) createCanvasV1alpha1(c *gin.Context) {
 	var req dto.CreateCanvasRequest
@@ -24,13 +36,16 @@ func (s *Server) createCanvasV1alpha1(c *gin.Context) {
 		ValidationError(c, err)
 		return
 	}
+	c.Set(auditCanvasContextKey, req.Name)
 
-	canvas, err := s.canvasService.Create(c.Request.Context(), req.Name, req.DisplayName)
-	if err != nil {
-		s.logger.Error(err, "failed to create canvas")
-		InternalServerError(c, err, "failed to create canvas")
-		return
-	}
+	s.idempotent(c, req, func() {
+		canvas, err := s.canvasService.Create(c.Request.Context(), req.Name, req.DisplayName)
+		if err != nil {
+			s.logKubernetesError(c, err, "failed to create canvas")
+			KubernetesError(c, err, "failed to create canvas")
+			return
+		}
 
-	c.JSON(http.StatusCreated, dto.CanvasFromV1Alpha1(canvas))
+		writeCanvas(c, http.StatusCreated, dto.CanvasFromV1Alpha1(canvas))
+	})
 }
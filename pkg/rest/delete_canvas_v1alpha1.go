@@ -0,0 +1,47 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	// The error responses are only referenced by the API docs.
+	_ "github.com/orray-proj/orray/pkg/rest/dto"
+)
+
+// @id DeleteCanvasV1alpha1
+// @Summary Delete a canvas
+// @Description Delete a canvas by name
+// @Tags Canvas
+// @Produce json
+// @Param name path string true "Canvas name"
+// @Param If-Match header string false "ETags the canvas must still match one of to be deleted, or *"
+// @Success 204
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 403 {object} dto.ErrorResponse "Forbidden"
+// @Failure 404 {object} dto.ErrorResponse "Not Found"
+// @Failure 409 {object} dto.ErrorResponse "Conflict"
+// @Failure 412 {object} dto.ErrorResponse "Precondition Failed"
+// @Failure 429 {object} dto.ErrorResponse "Too Many Requests"
+// @Failure 500 {object} dto.ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /v1alpha1/canvases/{name} [delete]
+func (s *Server) deleteCanvasV1alpha1(c *gin.Context) {
+	name := c.Param("name")
+
+	preconditions, ok := ifMatchPreconditions(c)
+	if !ok {
+		return
+	}
+
+	if err := s.canvasService.Delete(c.Request.Context(), name, preconditions); err != nil {
+		if preconditionFailed(c, err) {
+			return
+		}
+		s.logKubernetesError(c, err, "failed to delete canvas", "name", name)
+		KubernetesError(c, err, "failed to delete canvas")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
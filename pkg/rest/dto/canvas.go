@@ -1,6 +1,11 @@
 package dto
 
-import "github.com/orray-proj/orray/api/v1alpha1"
+import (
+	"time"
+
+	"github.com/orray-proj/orray/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+)
 
 // CreateCanvasRequest is the request body for creating a canvas.
 type CreateCanvasRequest struct {
@@ -8,19 +13,82 @@ type CreateCanvasRequest struct {
 	DisplayName string `json:"displayName" binding:"required"`
 }
 
+// UpdateCanvasRequest is the request body for replacing a canvas.
+type UpdateCanvasRequest struct {
+	DisplayName string `json:"displayName" binding:"required"`
+}
+
+// PatchCanvasRequest is the request body for partially updating a canvas.
+// Omitted fields are left unchanged.
+type PatchCanvasRequest struct {
+	DisplayName *string `json:"displayName,omitempty" binding:"omitempty,min=1"`
+}
+
+// CanvasFilterRequest contains the query parameters for filtering and sorting
+// canvas lists. Filters are applied before pagination.
+type CanvasFilterRequest struct {
+	// LabelSelector restricts the list to canvases whose labels match, e.g. "team=payments".
+	LabelSelector string `form:"labelSelector" binding:"omitempty,labelselector"`
+	// Q is matched case-insensitively against the name and display name.
+	Q string `form:"q"`
+	// Phase restricts the list to canvases in the given phase.
+	Phase string `form:"phase" binding:"omitempty,oneof=Ready Provisioning Failed" enums:"Ready,Provisioning,Failed"`
+	// Sort orders the list by a field. A leading dash sorts in descending order.
+	Sort string `form:"sort" binding:"omitempty,oneof=name -name createdAt -createdAt displayName -displayName" enums:"name,-name,createdAt,-createdAt,displayName,-displayName"`
+}
+
 // Canvas is a minimal wrapper around the spec from the v1alpha1 api
 type Canvas struct {
 	v1alpha1.CanvasSpec
 
 	Id   string `json:"id" binding:"required"`
 	Name string `json:"name" binding:"required"`
+	// ResourceVersion changes on every write to the canvas. It is also sent
+	// as the ETag of canvas responses.
+	ResourceVersion string `json:"resourceVersion" binding:"required"`
+	// Namespace is the name of the home namespace provisioned for the canvas.
+	Namespace string `json:"namespace" binding:"required"`
+	// CreatedAt is the time the canvas was created.
+	CreatedAt time.Time `json:"createdAt" binding:"required"`
+	// Status summarizes the provisioning state of the canvas.
+	Status CanvasStatus `json:"status" binding:"required"`
+}
+
+// CanvasStatus summarizes the provisioning state of a canvas.
+type CanvasStatus struct {
+	// Phase is derived from the Ready condition of the canvas.
+	Phase string `json:"phase" binding:"required" enums:"Provisioning,Ready,Failed"`
+	// Reason is the machine-readable reason of the Ready condition.
+	Reason string `json:"reason,omitempty"`
+	// Message is the human-readable message of the Ready condition.
+	Message string `json:"message,omitempty"`
+	// ObservedGeneration is the generation of the canvas last reconciled by
+	// the controller.
+	ObservedGeneration int64 `json:"observedGeneration"`
+	// Namespaces are the namespaces the canvas spans, including its home
+	// namespace.
+	Namespaces []string `json:"namespaces,omitempty"`
 }
 
 // CanvasFromV1Alpha1 convert a convas to its DTO
 func CanvasFromV1Alpha1(c *v1alpha1.Canvas) Canvas {
+	status := CanvasStatus{
+		Phase:              string(c.Status.Phase()),
+		ObservedGeneration: c.Status.ObservedGeneration,
+		Namespaces:         c.Status.Namespaces,
+	}
+	if ready := meta.FindStatusCondition(c.Status.Conditions, v1alpha1.ConditionTypeReady); ready != nil {
+		status.Reason = ready.Reason
+		status.Message = ready.Message
+	}
+
 	return Canvas{
-		CanvasSpec: c.Spec,
-		Id:         string(c.UID),
-		Name:       c.Name,
+		CanvasSpec:      c.Spec,
+		Id:              string(c.UID),
+		Name:            c.Name,
+		ResourceVersion: c.ResourceVersion,
+		Namespace:       c.HomeNamespace(),
+		CreatedAt:       c.CreationTimestamp.UTC(),
+		Status:          status,
 	}
 }
@@ -0,0 +1,111 @@
+package dto
+
+import (
+	"testing"
+	"time"
+
+	"github.com/orray-proj/orray/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCanvasFromV1Alpha1(t *testing.T) {
+	created := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		conditions []metav1.Condition
+		expected   CanvasStatus
+	}{
+		{
+			name:     "no conditions",
+			expected: CanvasStatus{Phase: "Provisioning", ObservedGeneration: 1},
+		},
+		{
+			name: "provisioning",
+			conditions: []metav1.Condition{{
+				Type:    v1alpha1.ConditionTypeReady,
+				Status:  metav1.ConditionFalse,
+				Reason:  v1alpha1.ReasonProvisioning,
+				Message: "Provisioning started",
+			}},
+			expected: CanvasStatus{
+				Phase:              "Provisioning",
+				Reason:             v1alpha1.ReasonProvisioning,
+				Message:            "Provisioning started",
+				ObservedGeneration: 1,
+			},
+		},
+		{
+			name: "ready",
+			conditions: []metav1.Condition{{
+				Type:    v1alpha1.ConditionTypeReady,
+				Status:  metav1.ConditionTrue,
+				Reason:  v1alpha1.ReasonProvisioned,
+				Message: "Canvas provisioned successfully",
+			}},
+			expected: CanvasStatus{
+				Phase:              "Ready",
+				Reason:             v1alpha1.ReasonProvisioned,
+				Message:            "Canvas provisioned successfully",
+				ObservedGeneration: 1,
+			},
+		},
+		{
+			name: "failed",
+			conditions: []metav1.Condition{{
+				Type:    v1alpha1.ConditionTypeReady,
+				Status:  metav1.ConditionFalse,
+				Reason:  v1alpha1.ReasonFailed,
+				Message: "Failed to sync namespace",
+			}},
+			expected: CanvasStatus{
+				Phase:              "Failed",
+				Reason:             v1alpha1.ReasonFailed,
+				Message:            "Failed to sync namespace",
+				ObservedGeneration: 1,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			canvas := &v1alpha1.Canvas{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "test",
+					UID:               "uid",
+					ResourceVersion:   "42",
+					CreationTimestamp: metav1.NewTime(created),
+				},
+				Spec: v1alpha1.CanvasSpec{DisplayName: "Test Canvas"},
+				Status: v1alpha1.CanvasStatus{
+					Conditions:         tt.conditions,
+					ObservedGeneration: 1,
+				},
+			}
+
+			result := CanvasFromV1Alpha1(canvas)
+
+			assert.Equal(t, "uid", result.Id)
+			assert.Equal(t, "test", result.Name)
+			assert.Equal(t, "42", result.ResourceVersion)
+			assert.Equal(t, "test", result.Namespace)
+			assert.Equal(t, "Test Canvas", result.DisplayName)
+			assert.Equal(t, created, result.CreatedAt)
+			assert.Equal(t, tt.expected, result.Status)
+		})
+	}
+}
+
+func TestCanvasFromV1Alpha1HomeNamespace(t *testing.T) {
+	canvas := &v1alpha1.Canvas{
+		ObjectMeta: metav1.ObjectMeta{Name: "test"},
+		Spec:       v1alpha1.CanvasSpec{DisplayName: "Test Canvas", HomeNamespace: "payments"},
+		Status:     v1alpha1.CanvasStatus{Namespaces: []string{"ingress", "payments"}},
+	}
+
+	result := CanvasFromV1Alpha1(canvas)
+
+	assert.Equal(t, "payments", result.Namespace)
+	assert.Equal(t, []string{"ingress", "payments"}, result.Status.Namespaces)
+}
@@ -11,3 +11,34 @@ type ErrorResponse struct {
 	// RequestID is a unique identifier for the request, useful for debugging.
 	RequestID string `json:"requestId,omitempty"`
 }
+
+// FieldError describes a problem with a single field of a request.
+type FieldError struct {
+	// Field is the JSON name of the offending field, e.g. "displayName".
+	Field string `json:"field"`
+	// JSONPath locates the offending field in the request, e.g. "$.displayName".
+	// It is empty when the error does not refer to the request body.
+	JSONPath string `json:"jsonPath,omitempty"`
+	// Tag is the validation rule that failed, e.g. "required" or "max".
+	Tag string `json:"tag,omitempty"`
+	// Param is the parameter of the failed validation rule, e.g. "100" for "max=100".
+	Param string `json:"param,omitempty"`
+	// Reason is a machine-readable description of the problem reported by
+	// Kubernetes, when known.
+	Reason string `json:"reason,omitempty"`
+	// Message is a human-readable description of the problem.
+	Message string `json:"message"`
+}
+
+// ValidationErrorResponse documents the shape of ErrorResponse when a request
+// fails validation.
+type ValidationErrorResponse struct {
+	// Message is a human-readable description of the error.
+	Message string `json:"message"`
+	// Code is a machine-readable error code.
+	Code string `json:"code,omitempty"`
+	// Details lists the fields that failed validation.
+	Details []FieldError `json:"details,omitempty"`
+	// RequestID is a unique identifier for the request, useful for debugging.
+	RequestID string `json:"requestId,omitempty"`
+}
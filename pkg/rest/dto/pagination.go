@@ -1,21 +1,41 @@
 package dto
 
+import metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
 // PaginationRequest contains the standard query parameters for list requests.
+//
+// Requests without an offset are served a page at a time by the Kubernetes API
+// and return a continue cursor for the next page. Requests with an offset load
+// the whole collection and slice it in memory, which is only suitable for
+// small lists.
 type PaginationRequest struct {
 	// Limit is the maximum number of items to return.
 	Limit int `form:"limit,default=10" binding:"min=1,max=100"`
 	// Offset is the number of items to skip.
 	Offset int `form:"offset,default=0" binding:"min=0"`
+	// Continue is the cursor returned by the previous page. It cannot be
+	// combined with offset.
+	Continue string `form:"continue" binding:"excluded_unless=Offset 0"`
+}
+
+// UsesCursor reports whether the request is served using Kubernetes
+// continue tokens rather than in-memory offsets.
+func (r PaginationRequest) UsesCursor() bool {
+	return r.Offset == 0
 }
 
 // Pagination contains pagination metadata for list responses.
 type Pagination struct {
-	// Total is the total number of items available.
+	// Total is the total number of items available. When paginating with a
+	// cursor it is a lower bound if the remaining items cannot be counted.
 	Total int64 `json:"total"`
 	// Limit is the maximum number of items requested.
 	Limit int `json:"limit"`
 	// Offset is the number of items skipped.
 	Offset int `json:"offset"`
+	// Continue is the cursor to fetch the next page with. It is empty on the
+	// last page.
+	Continue string `json:"continue,omitempty"`
 }
 
 // ListResponse is a generic wrapper for paginated list responses.
@@ -38,17 +58,17 @@ func NewListResponse[T any](items []T, total int64, limit, offset int) ListRespo
 	}
 }
 
-// Paginate takes a full slice of items and a PaginationRequest, 
+// Paginate takes a full slice of items and a PaginationRequest,
 // applies the slicing, and returns a ListResponse with mapped items.
 // This is useful for in-memory pagination (e.g. from Kubernetes client results).
 func Paginate[T any, R any](items []T, req PaginationRequest, mapper func(T) R) ListResponse[R] {
 	total := int64(len(items))
-	
+
 	start := req.Offset
 	if start > int(total) {
 		start = int(total)
 	}
-	
+
 	end := start + req.Limit
 	if end > int(total) {
 		end = int(total)
@@ -58,6 +78,26 @@ func Paginate[T any, R any](items []T, req PaginationRequest, mapper func(T) R)
 	return NewListResponse(result, total, req.Limit, req.Offset)
 }
 
+// PaginateList builds a ListResponse from the result of a Kubernetes list call
+// made with the limit and continue token of req. Requests that use offsets, or
+// results that were not limited by the server, fall back to Paginate.
+func PaginateList[T any, R any](
+	items []T, listMeta metav1.ListMeta, req PaginationRequest, mapper func(T) R,
+) ListResponse[R] {
+	if !req.UsesCursor() || len(items) > req.Limit {
+		return Paginate(items, req, mapper)
+	}
+
+	total := int64(len(items))
+	if listMeta.RemainingItemCount != nil {
+		total += *listMeta.RemainingItemCount
+	}
+
+	resp := NewListResponse(MapSlice(items, mapper), total, req.Limit, req.Offset)
+	resp.Pagination.Continue = listMeta.Continue
+	return resp
+}
+
 // MapSlice is a generic utility to map a slice from one type to another.
 func MapSlice[T any, R any](items []T, mapper func(T) R) []R {
 	if items == nil {
@@ -0,0 +1,43 @@
+package dto
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+func TestPaginateList(t *testing.T) {
+	double := func(i int) string { return strconv.Itoa(i * 2) }
+
+	t.Run("CursorPage", func(t *testing.T) {
+		listMeta := metav1.ListMeta{Continue: "next", RemainingItemCount: ptr.To[int64](3)}
+		resp := PaginateList([]int{1, 2}, listMeta, PaginationRequest{Limit: 2}, double)
+
+		assert.Equal(t, []string{"2", "4"}, resp.Items)
+		assert.Equal(t, Pagination{Total: 5, Limit: 2, Continue: "next"}, resp.Pagination)
+	})
+
+	t.Run("LastCursorPage", func(t *testing.T) {
+		resp := PaginateList([]int{1}, metav1.ListMeta{}, PaginationRequest{Limit: 2, Continue: "next"}, double)
+
+		assert.Equal(t, []string{"2"}, resp.Items)
+		assert.Equal(t, Pagination{Total: 1, Limit: 2}, resp.Pagination)
+	})
+
+	t.Run("OffsetPage", func(t *testing.T) {
+		resp := PaginateList([]int{1, 2, 3, 4}, metav1.ListMeta{}, PaginationRequest{Limit: 2, Offset: 1}, double)
+
+		assert.Equal(t, []string{"4", "6"}, resp.Items)
+		assert.Equal(t, Pagination{Total: 4, Limit: 2, Offset: 1}, resp.Pagination)
+	})
+
+	t.Run("UnlimitedResult", func(t *testing.T) {
+		resp := PaginateList([]int{1, 2, 3}, metav1.ListMeta{}, PaginationRequest{Limit: 2}, double)
+
+		assert.Equal(t, []string{"2", "4"}, resp.Items)
+		assert.Equal(t, Pagination{Total: 3, Limit: 2}, resp.Pagination)
+	})
+}
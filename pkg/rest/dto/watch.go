@@ -0,0 +1,11 @@
+package dto
+
+// WatchRequest contains the query parameters for watching a collection.
+type WatchRequest struct {
+	// Watch streams ADDED, MODIFIED and DELETED events as Server-Sent Events
+	// instead of returning a list.
+	Watch bool `form:"watch"`
+	// ResourceVersion resumes a watch after the given version. The
+	// Last-Event-ID header is used when it is not set.
+	ResourceVersion string `form:"resourceVersion"`
+}
@@ -1,17 +1,26 @@
 package rest
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/orray-proj/orray/pkg/rest/dto"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+// errorCodeContextKey is the gin context key holding the code of the error
+// the request failed with.
+const errorCodeContextKey = "errorCode"
+
 // AbortWithError sends a standardized error response and aborts the request.
 func AbortWithError(c *gin.Context, statusCode int, code string, message string, details any) {
 	requestID := c.GetString("requestId")
-	
+
 	resp := dto.ErrorResponse{
 		Code:      code,
 		Message:   message,
@@ -19,6 +28,7 @@ func AbortWithError(c *gin.Context, statusCode int, code string, message string,
 		RequestID: requestID,
 	}
 
+	c.Set(errorCodeContextKey, code)
 	c.AbortWithStatusJSON(statusCode, resp)
 }
 
@@ -47,8 +57,165 @@ func NotFound(c *gin.Context, message string) {
 	AbortWithError(c, http.StatusNotFound, "NOT_FOUND", message, nil)
 }
 
-// ValidationError maps binding errors to a standardized format.
+// Unauthorized responds with a 401 status code and asks the client to
+// authenticate with a bearer token.
+func Unauthorized(c *gin.Context, message string) {
+	if message == "" {
+		message = "Authentication required"
+	}
+	c.Header("WWW-Authenticate", `Bearer realm="orray"`)
+	AbortWithError(c, http.StatusUnauthorized, "UNAUTHORIZED", message, nil)
+}
+
+// ServiceUnavailable responds with a 503 status code.
+func ServiceUnavailable(c *gin.Context, message string) {
+	if message == "" {
+		message = "Service temporarily unavailable"
+	}
+	AbortWithError(c, http.StatusServiceUnavailable, "SERVICE_UNAVAILABLE", message, nil)
+}
+
+// Conflict responds with a 409 status code.
+func Conflict(c *gin.Context, message string) {
+	if message == "" {
+		message = "Resource conflict"
+	}
+	AbortWithError(c, http.StatusConflict, "CONFLICT", message, nil)
+}
+
+// PreconditionFailed responds with a 412 status code.
+func PreconditionFailed(c *gin.Context, message string) {
+	if message == "" {
+		message = "Precondition failed"
+	}
+	AbortWithError(c, http.StatusPreconditionFailed, "PRECONDITION_FAILED", message, nil)
+}
+
+// TooManyRequests responds with a 429 status code and tells the client how
+// long to wait before retrying.
+func TooManyRequests(c *gin.Context, retryAfter time.Duration) {
+	c.Header("Retry-After", retryAfterSeconds(retryAfter))
+	AbortWithError(c, http.StatusTooManyRequests, "RATE_LIMITED", "Too many requests, please retry later", nil)
+}
+
+// RequestEntityTooLarge responds with a 413 status code.
+func RequestEntityTooLarge(c *gin.Context, maxBytes int64) {
+	AbortWithError(c, http.StatusRequestEntityTooLarge, "REQUEST_TOO_LARGE",
+		fmt.Sprintf("Request body must not exceed %d bytes", maxBytes), nil)
+}
+
+// ValidationError maps the errors of binding the request body to a
+// standardized format with field-level details.
 func ValidationError(c *gin.Context, err error) {
-	// In a real app, we might parse the gin binding error to provide field-level details.
-	BadRequest(c, "VALIDATION_ERROR", "Validation failed", fmt.Sprintf("%v", err))
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		RequestEntityTooLarge(c, maxBytesErr.Limit)
+		return
+	}
+	validationError(c, err, true)
+}
+
+// QueryValidationError maps the errors of binding query parameters to a
+// standardized format with field-level details.
+func QueryValidationError(c *gin.Context, err error) {
+	validationError(c, err, false)
+}
+
+func validationError(c *gin.Context, err error, inBody bool) {
+	fieldErrors := fieldErrorsFromBinding(err, inBody)
+	if len(fieldErrors) == 0 {
+		BadRequest(c, "VALIDATION_ERROR", fmt.Sprintf("Invalid request: %v", err), nil)
+		return
+	}
+	BadRequest(c, "VALIDATION_ERROR", "Validation failed", fieldErrors)
+}
+
+// kubernetesStatusErrors maps Kubernetes status reasons to the HTTP status code
+// and error code returned to API clients.
+var kubernetesStatusErrors = map[metav1.StatusReason]struct {
+	status int
+	code   string
+}{
+	metav1.StatusReasonNotFound:        {http.StatusNotFound, "NOT_FOUND"},
+	metav1.StatusReasonAlreadyExists:   {http.StatusConflict, "ALREADY_EXISTS"},
+	metav1.StatusReasonConflict:        {http.StatusConflict, "CONFLICT"},
+	metav1.StatusReasonExpired:         {http.StatusGone, "EXPIRED"},
+	metav1.StatusReasonGone:            {http.StatusGone, "EXPIRED"},
+	metav1.StatusReasonInvalid:         {http.StatusUnprocessableEntity, "INVALID"},
+	metav1.StatusReasonForbidden:       {http.StatusForbidden, "FORBIDDEN"},
+	metav1.StatusReasonUnauthorized:    {http.StatusUnauthorized, "UNAUTHORIZED"},
+	metav1.StatusReasonTooManyRequests: {http.StatusTooManyRequests, "TOO_MANY_REQUESTS"},
+	metav1.StatusReasonTimeout:         {http.StatusGatewayTimeout, "TIMEOUT"},
+	metav1.StatusReasonServerTimeout:   {http.StatusGatewayTimeout, "TIMEOUT"},
+}
+
+// KubernetesError translates an error returned by the Kubernetes API into a
+// standardized error response. Errors without a known status reason are
+// reported as internal server errors using the given message.
+func KubernetesError(c *gin.Context, err error, message string) {
+	statusCode, resp, ok := kubernetesErrorResponse(c, err)
+	if !ok {
+		InternalServerError(c, err, message)
+		return
+	}
+
+	if seconds, delay := apierrors.SuggestsClientDelay(err); delay {
+		c.Header("Retry-After", strconv.Itoa(seconds))
+	}
+	c.Set(errorCodeContextKey, resp.Code)
+	c.AbortWithStatusJSON(statusCode, resp)
+}
+
+// logKubernetesError logs an error returned by the Kubernetes API. Errors
+// reported as server errors are logged at the error level, the others, such
+// as missing canvases or failed preconditions, are the client's doing and only
+// logged at the debug level.
+func (s *Server) logKubernetesError(c *gin.Context, err error, message string, keysAndValues ...any) {
+	if statusCode, _, ok := kubernetesErrorResponse(c, err); ok && statusCode < http.StatusInternalServerError {
+		s.requestLogger(c).Debug(message, append(keysAndValues, "error", err.Error())...)
+		return
+	}
+	s.requestLogger(c).Error(err, message, keysAndValues...)
+}
+
+// kubernetesErrorResponse builds the error response for an error returned by
+// the Kubernetes API. It returns false if the error has no known status reason.
+func kubernetesErrorResponse(c *gin.Context, err error) (int, dto.ErrorResponse, bool) {
+	var statusErr apierrors.APIStatus
+	if !errors.As(err, &statusErr) {
+		return 0, dto.ErrorResponse{}, false
+	}
+
+	status := statusErr.Status()
+	mapped, ok := kubernetesStatusErrors[status.Reason]
+	if !ok {
+		return 0, dto.ErrorResponse{}, false
+	}
+
+	resp := dto.ErrorResponse{
+		Code:      mapped.code,
+		Message:   status.Message,
+		RequestID: c.GetString("requestId"),
+	}
+	if causes := fieldErrorsFromStatus(status); len(causes) > 0 {
+		resp.Details = causes
+	}
+	return mapped.status, resp, true
+}
+
+// fieldErrorsFromStatus extracts the field-level causes of a Kubernetes status.
+func fieldErrorsFromStatus(status metav1.Status) []dto.FieldError {
+	if status.Details == nil {
+		return nil
+	}
+
+	fieldErrors := make([]dto.FieldError, 0, len(status.Details.Causes))
+	for _, cause := range status.Details.Causes {
+		fieldErrors = append(fieldErrors, dto.FieldError{
+			Field:   cause.Field,
+			Reason:  string(cause.Type),
+			Message: cause.Message,
+		})
+	}
+	return fieldErrors
 }
@@ -0,0 +1,154 @@
+package rest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/orray-proj/orray/api/v1alpha1"
+	"github.com/orray-proj/orray/pkg/logging"
+	"github.com/orray-proj/orray/pkg/rest/dto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+func TestKubernetesError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	resource := v1alpha1.GroupVersion.WithResource("canvases").GroupResource()
+	kind := v1alpha1.GroupVersion.WithKind("Canvas").GroupKind()
+
+	tests := []struct {
+		name           string
+		err            error
+		expectedStatus int
+		expectedCode   string
+		expectedFields []dto.FieldError
+		retryAfter     string
+	}{
+		{
+			name:           "not found",
+			err:            apierrors.NewNotFound(resource, "test"),
+			expectedStatus: http.StatusNotFound,
+			expectedCode:   "NOT_FOUND",
+		},
+		{
+			name:           "already exists",
+			err:            apierrors.NewAlreadyExists(resource, "test"),
+			expectedStatus: http.StatusConflict,
+			expectedCode:   "ALREADY_EXISTS",
+		},
+		{
+			name:           "conflict",
+			err:            apierrors.NewConflict(resource, "test", errors.New("modified")),
+			expectedStatus: http.StatusConflict,
+			expectedCode:   "CONFLICT",
+		},
+		{
+			name: "invalid",
+			err: apierrors.NewInvalid(kind, "test", field.ErrorList{
+				field.Required(field.NewPath("spec", "displayName"), "display name is required"),
+			}),
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedCode:   "INVALID",
+			expectedFields: []dto.FieldError{
+				{
+					Field:   "spec.displayName",
+					Reason:  "FieldValueRequired",
+					Message: "Required value: display name is required",
+				},
+			},
+		},
+		{
+			name:           "forbidden",
+			err:            apierrors.NewForbidden(resource, "test", errors.New("denied")),
+			expectedStatus: http.StatusForbidden,
+			expectedCode:   "FORBIDDEN",
+		},
+		{
+			name:           "unauthorized",
+			err:            apierrors.NewUnauthorized("no credentials"),
+			expectedStatus: http.StatusUnauthorized,
+			expectedCode:   "UNAUTHORIZED",
+		},
+		{
+			name:           "too many requests",
+			err:            apierrors.NewTooManyRequests("slow down", 5),
+			expectedStatus: http.StatusTooManyRequests,
+			expectedCode:   "TOO_MANY_REQUESTS",
+			retryAfter:     "5",
+		},
+		{
+			name:           "timeout",
+			err:            apierrors.NewTimeoutError("timed out", 0),
+			expectedStatus: http.StatusGatewayTimeout,
+			expectedCode:   "TIMEOUT",
+		},
+		{
+			name:           "unknown error",
+			err:            errors.New("boom"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedCode:   "INTERNAL_SERVER_ERROR",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+
+			KubernetesError(c, tt.err, "failed")
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.retryAfter, w.Header().Get("Retry-After"))
+
+			var resp struct {
+				dto.ErrorResponse
+				Details []dto.FieldError `json:"details"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			assert.Equal(t, tt.expectedCode, resp.Code)
+			assert.Equal(t, tt.expectedFields, resp.Details)
+		})
+	}
+}
+
+func TestLogKubernetesError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	resource := v1alpha1.GroupVersion.WithResource("canvases").GroupResource()
+
+	tests := []struct {
+		name   string
+		err    error
+		logged bool
+	}{
+		{name: "not found", err: apierrors.NewNotFound(resource, "test")},
+		{name: "conflict", err: apierrors.NewConflict(resource, "test", errors.New("modified"))},
+		{name: "timeout", err: apierrors.NewTimeoutError("timed out", 0), logged: true},
+		{name: "unknown error", err: errors.New("boom"), logged: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logs bytes.Buffer
+			logger, err := logging.NewLoggerWithWriter(logging.InfoLevel, logging.JSONFormat, &logs)
+			require.NoError(t, err)
+			server := &Server{logger: logger}
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+
+			server.logKubernetesError(c, tt.err, "failed")
+
+			if tt.logged {
+				assert.Contains(t, logs.String(), `"level":"error"`)
+			} else {
+				assert.Empty(t, logs.String())
+			}
+		})
+	}
+}
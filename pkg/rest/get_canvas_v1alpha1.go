@@ -0,0 +1,45 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/orray-proj/orray/pkg/rest/dto"
+)
+
+// @id GetCanvasV1alpha1
+// @Summary Get a canvas
+// @Description Get a single canvas by name. The response carries the resource version of the canvas as its ETag,
+// @Description which can be sent in If-None-Match to skip unchanged canvases.
+// @Tags Canvas
+// @Produce json
+// @Param name path string true "Canvas name"
+// @Param If-None-Match header string false "ETag of the client's copy of the canvas"
+// @Success 200 {object} dto.Canvas
+// @Header 200 {string} ETag "Resource version of the canvas"
+// @Success 304 "Not Modified"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 403 {object} dto.ErrorResponse "Forbidden"
+// @Failure 404 {object} dto.ErrorResponse "Not Found"
+// @Failure 429 {object} dto.ErrorResponse "Too Many Requests"
+// @Failure 500 {object} dto.ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /v1alpha1/canvases/{name} [get]
+func (s *Server) getCanvasV1alpha1(c *gin.Context) {
+	name := c.Param("name")
+
+	canvas, err := s.canvasService.Get(c.Request.Context(), name)
+	if err != nil {
+		s.logKubernetesError(c, err, "failed to get canvas", "name", name)
+		KubernetesError(c, err, "failed to get canvas")
+		return
+	}
+
+	resp := dto.CanvasFromV1Alpha1(canvas)
+	if ifNoneMatch(c, canvasETag(resp)) {
+		c.Header("ETag", canvasETag(resp))
+		c.Status(http.StatusNotModified)
+		return
+	}
+	writeCanvas(c, http.StatusOK, resp)
+}
@@ -0,0 +1,24 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	// The error responses are only referenced by the API docs.
+	_ "github.com/orray-proj/orray/pkg/rest/dto"
+	"github.com/orray-proj/orray/pkg/version"
+)
+
+// @id GetVersion
+// @Summary Get the server version
+// @Description Get version and build information about the API server
+// @Tags System
+// @Produce json
+// @Success 200 {object} version.Version
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 429 {object} dto.ErrorResponse "Too Many Requests"
+// @Security BearerAuth
+// @Router /version [get]
+func (s *Server) getVersion(c *gin.Context) {
+	c.JSON(http.StatusOK, version.GetVersion())
+}
@@ -0,0 +1,56 @@
+package rest
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+	orrayv1alpha1 "github.com/orray-proj/orray/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/discovery"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+)
+
+// canvasesResource is the plural name under which Canvas resources are served.
+const canvasesResource = "canvases"
+
+// healthHandler serves the checks of a healthz.Handler mounted at prefix,
+// both aggregated and individually under prefix/<check>.
+func healthHandler(prefix string, checks map[string]healthz.Checker) gin.HandlerFunc {
+	return gin.WrapH(http.StripPrefix(prefix, &healthz.Handler{Checks: checks}))
+}
+
+// livenessChecks are served on /healthz. The server is alive as long as it
+// answers.
+func livenessChecks() map[string]healthz.Checker {
+	return map[string]healthz.Checker{
+		"ping": healthz.Ping,
+	}
+}
+
+// readinessChecks are served on /readyz. The server is ready once the
+// Kubernetes API answers and serves Canvas resources.
+func readinessChecks(client discovery.DiscoveryInterface) map[string]healthz.Checker {
+	return map[string]healthz.Checker{
+		"kubernetes-api": func(*http.Request) error {
+			if _, err := client.ServerVersion(); err != nil {
+				return fmt.Errorf("kubernetes API is not reachable: %w", err)
+			}
+			return nil
+		},
+		"canvas-crd": func(*http.Request) error {
+			groupVersion := orrayv1alpha1.GroupVersion.String()
+			resources, err := client.ServerResourcesForGroupVersion(groupVersion)
+			if err != nil {
+				return fmt.Errorf("failed to discover %s: %w", groupVersion, err)
+			}
+			if !slices.ContainsFunc(resources.APIResources, func(resource metav1.APIResource) bool {
+				return resource.Name == canvasesResource
+			}) {
+				return fmt.Errorf("%s.%s is not served", canvasesResource, orrayv1alpha1.GroupVersion.Group)
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,70 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/orray-proj/orray/api/v1alpha1"
+	"github.com/orray-proj/orray/pkg/logging"
+	"github.com/orray-proj/orray/pkg/version"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	fakeclientset "k8s.io/client-go/kubernetes/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestHealthEndpoints(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1alpha1.AddToScheme(scheme))
+
+	logger, _ := logging.NewLogger(logging.DebugLevel, logging.ConsoleFormat)
+	clientset := fakeclientset.NewClientset()
+	discovery := clientset.Discovery().(*fakediscovery.FakeDiscovery)
+	server, err := NewServer(
+		context.Background(), &Config{Mode: "test"}, logger,
+		fake.NewClientBuilder().WithScheme(scheme).Build(), clientset,
+	)
+	require.NoError(t, err)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	t.Run("Liveness", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, get("/healthz").Code)
+		assert.Equal(t, http.StatusOK, get("/healthz/ping").Code)
+	})
+
+	t.Run("NotReadyWithoutCRD", func(t *testing.T) {
+		assert.Equal(t, http.StatusInternalServerError, get("/readyz").Code)
+		assert.Equal(t, http.StatusOK, get("/readyz/kubernetes-api").Code)
+		assert.Equal(t, http.StatusInternalServerError, get("/readyz/canvas-crd").Code)
+	})
+
+	t.Run("Ready", func(t *testing.T) {
+		discovery.Resources = []*metav1.APIResourceList{{
+			GroupVersion: v1alpha1.GroupVersion.String(),
+			APIResources: []metav1.APIResource{{Name: "canvases", Kind: "Canvas"}},
+		}}
+
+		assert.Equal(t, http.StatusOK, get("/readyz").Code)
+		assert.Equal(t, http.StatusOK, get("/readyz/canvas-crd").Code)
+	})
+
+	t.Run("Version", func(t *testing.T) {
+		w := get("/api/version")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var v version.Version
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &v))
+		assert.Equal(t, version.GetVersion(), v)
+	})
+}
@@ -0,0 +1,255 @@
+package rest
+
+import (
+	"bytes"
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// idempotencyKeyHeader is the request header carrying the idempotency key.
+	idempotencyKeyHeader = "Idempotency-Key"
+	// idempotentReplayedHeader marks the responses replayed from the store.
+	idempotentReplayedHeader = "Idempotent-Replayed"
+	// maxIdempotencyKeyLength bounds the memory used by a single key.
+	maxIdempotencyKeyLength = 255
+	// maxIdempotencyKeys bounds the number of keys a memoryIdempotencyStore
+	// remembers.
+	maxIdempotencyKeys = 4096
+)
+
+// replayedHeaders are the response headers recorded along with the body.
+// Headers describing the request, such as X-Request-ID, are not replayed.
+var replayedHeaders = []string{"Content-Type", "ETag"}
+
+// IdempotentResponse is a response recorded for an idempotency key.
+type IdempotentResponse struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// IdempotencyRecord is what an IdempotencyStore knows about a key.
+type IdempotencyRecord struct {
+	// Fingerprint identifies the request the key was first used with.
+	Fingerprint string
+	// Response is the response to that request, or nil while it is still
+	// being handled.
+	Response *IdempotentResponse
+}
+
+// IdempotencyStore remembers the requests sent with an idempotency key and
+// their responses.
+type IdempotencyStore interface {
+	// Reserve claims key for the request identified by fingerprint. If key
+	// is already known, its record is returned along with false.
+	Reserve(key, fingerprint string) (IdempotencyRecord, bool)
+	// Complete records the response to the request that reserved key.
+	Complete(key string, response IdempotentResponse)
+	// Release forgets key, so that the request can be retried.
+	Release(key string)
+}
+
+type memoryIdempotencyEntry struct {
+	key     string
+	record  IdempotencyRecord
+	expires time.Time
+}
+
+// memoryIdempotencyStore keeps the keys in memory, so every replica of the
+// apiserver knows only about the requests it handled.
+type memoryIdempotencyStore struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	// order holds the entries from the oldest to the newest. As every key is
+	// kept for the same ttl, it is also the order in which they expire.
+	order *list.List
+}
+
+// NewMemoryIdempotencyStore returns an IdempotencyStore that keeps each key in
+// memory for ttl after it was first used. At most maxIdempotencyKeys are kept,
+// the oldest being forgotten first.
+func NewMemoryIdempotencyStore(ttl time.Duration) IdempotencyStore {
+	return &memoryIdempotencyStore{
+		ttl:     ttl,
+		now:     time.Now,
+		entries: map[string]*list.Element{},
+		order:   list.New(),
+	}
+}
+
+// Reserve implements IdempotencyStore.
+func (s *memoryIdempotencyStore) Reserve(key, fingerprint string) (IdempotencyRecord, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweep(s.now())
+	if element, ok := s.entries[key]; ok {
+		return element.Value.(*memoryIdempotencyEntry).record, false
+	}
+	if len(s.entries) >= maxIdempotencyKeys {
+		s.remove(s.order.Front())
+	}
+	s.entries[key] = s.order.PushBack(&memoryIdempotencyEntry{
+		key:     key,
+		record:  IdempotencyRecord{Fingerprint: fingerprint},
+		expires: s.now().Add(s.ttl),
+	})
+	return IdempotencyRecord{Fingerprint: fingerprint}, true
+}
+
+// Complete implements IdempotencyStore.
+func (s *memoryIdempotencyStore) Complete(key string, response IdempotentResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if element, ok := s.entries[key]; ok {
+		element.Value.(*memoryIdempotencyEntry).record.Response = &response
+	}
+}
+
+// Release implements IdempotencyStore.
+func (s *memoryIdempotencyStore) Release(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if element, ok := s.entries[key]; ok {
+		s.remove(element)
+	}
+}
+
+// sweep forgets the expired keys, which are the oldest ones.
+func (s *memoryIdempotencyStore) sweep(now time.Time) {
+	for element := s.order.Front(); element != nil; element = s.order.Front() {
+		if now.Before(element.Value.(*memoryIdempotencyEntry).expires) {
+			return
+		}
+		s.remove(element)
+	}
+}
+
+// remove forgets the key of element.
+func (s *memoryIdempotencyStore) remove(element *list.Element) {
+	entry := s.order.Remove(element).(*memoryIdempotencyEntry)
+	delete(s.entries, entry.key)
+}
+
+// recordingResponseWriter keeps a copy of the response body it writes.
+type recordingResponseWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *recordingResponseWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *recordingResponseWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// idempotent calls handle to process the request, unless the request carries
+// an Idempotency-Key that was already used by the same client. A repeated
+// request with the same content gets the recorded response of the first one,
+// while a key reused for different content is rejected. Only successful
+// responses are recorded, so that failed requests can be retried with the
+// same key.
+func (s *Server) idempotent(c *gin.Context, request any, handle func()) {
+	key := c.GetHeader(idempotencyKeyHeader)
+	if key == "" || s.idempotencyStore == nil {
+		handle()
+		return
+	}
+	if len(key) > maxIdempotencyKeyLength {
+		BadRequest(c, "INVALID_IDEMPOTENCY_KEY",
+			"Idempotency-Key must not be longer than 255 characters", nil)
+		return
+	}
+
+	fingerprint, err := requestFingerprint(c, request)
+	if err != nil {
+		InternalServerError(c, err, "")
+		return
+	}
+
+	// Keys are scoped to the client and the route, so that clients cannot
+	// read each other's responses.
+	scope := c.Request.Method + " " + c.FullPath()
+	if user, ok := currentUser(c); ok {
+		scope = "user:" + user.Username + " " + scope
+	}
+	storeKey := scope + " " + key
+
+	record, reserved := s.idempotencyStore.Reserve(storeKey, fingerprint)
+	if !reserved {
+		switch {
+		case record.Fingerprint != fingerprint:
+			AbortWithError(c, http.StatusUnprocessableEntity, "IDEMPOTENCY_KEY_REUSED",
+				"Idempotency-Key was already used for a different request", nil)
+		case record.Response == nil:
+			c.Header("Retry-After", "1")
+			AbortWithError(c, http.StatusConflict, "IDEMPOTENCY_KEY_IN_USE",
+				"A request with the same Idempotency-Key is still being processed", nil)
+		default:
+			for name, values := range record.Response.Header {
+				c.Writer.Header()[name] = values
+			}
+			c.Header(idempotentReplayedHeader, "true")
+			c.Data(record.Response.StatusCode, record.Response.Header.Get("Content-Type"), record.Response.Body)
+		}
+		return
+	}
+
+	recorder := &recordingResponseWriter{ResponseWriter: c.Writer}
+	c.Writer = recorder
+	completed := false
+	defer func() {
+		c.Writer = recorder.ResponseWriter
+		if !completed {
+			s.idempotencyStore.Release(storeKey)
+		}
+	}()
+
+	handle()
+
+	if status := recorder.Status(); status >= 200 && status < 300 {
+		header := http.Header{}
+		for _, name := range replayedHeaders {
+			if values := recorder.Header().Values(name); len(values) > 0 {
+				header[http.CanonicalHeaderKey(name)] = slices.Clone(values)
+			}
+		}
+		s.idempotencyStore.Complete(storeKey, IdempotentResponse{
+			StatusCode: status,
+			Header:     header,
+			Body:       bytes.Clone(recorder.body.Bytes()),
+		})
+		completed = true
+	}
+}
+
+// requestFingerprint identifies the content of a request by hashing its
+// decoded form, so that formatting differences do not matter.
+func requestFingerprint(c *gin.Context, request any) (string, error) {
+	data, err := json.Marshal(request)
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.New()
+	hash.Write([]byte(c.Request.Method + " " + c.Request.URL.Path + "\n"))
+	hash.Write(data)
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,177 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/orray-proj/orray/api/v1alpha1"
+	"github.com/orray-proj/orray/pkg/logging"
+	"github.com/orray-proj/orray/pkg/rest/dto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestMemoryIdempotencyStore(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := NewMemoryIdempotencyStore(time.Hour).(*memoryIdempotencyStore)
+	store.now = func() time.Time { return now }
+
+	_, reserved := store.Reserve("key", "first")
+	assert.True(t, reserved)
+
+	record, reserved := store.Reserve("key", "second")
+	assert.False(t, reserved)
+	assert.Equal(t, IdempotencyRecord{Fingerprint: "first"}, record)
+
+	response := IdempotentResponse{
+		StatusCode: http.StatusCreated,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       []byte("{}"),
+	}
+	store.Complete("key", response)
+	record, _ = store.Reserve("key", "first")
+	assert.Equal(t, &response, record.Response)
+
+	store.Release("key")
+	_, reserved = store.Reserve("key", "second")
+	assert.True(t, reserved)
+
+	now = now.Add(time.Hour)
+	record, reserved = store.Reserve("key", "third")
+	assert.True(t, reserved)
+	assert.Equal(t, "third", record.Fingerprint)
+}
+
+func TestMemoryIdempotencyStoreBounds(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := NewMemoryIdempotencyStore(time.Hour).(*memoryIdempotencyStore)
+	store.now = func() time.Time { return now }
+
+	for i := range maxIdempotencyKeys + 1 {
+		_, reserved := store.Reserve(strconv.Itoa(i), "request")
+		require.True(t, reserved)
+		now = now.Add(time.Millisecond)
+	}
+	assert.Len(t, store.entries, maxIdempotencyKeys)
+
+	// The oldest key was forgotten to make room for the newest.
+	_, reserved := store.Reserve(strconv.Itoa(maxIdempotencyKeys), "request")
+	assert.False(t, reserved)
+	_, reserved = store.Reserve("0", "request")
+	assert.True(t, reserved)
+
+	// Expired keys are forgotten as soon as another key is used.
+	now = now.Add(2 * time.Hour)
+	_, reserved = store.Reserve("new", "request")
+	assert.True(t, reserved)
+	assert.Len(t, store.entries, 1)
+	assert.Equal(t, 1, store.order.Len())
+}
+
+// inFlightIdempotencyStore pretends that every key is used by a request that
+// is still being processed.
+type inFlightIdempotencyStore struct{}
+
+func (s *inFlightIdempotencyStore) Reserve(_, fingerprint string) (IdempotencyRecord, bool) {
+	return IdempotencyRecord{Fingerprint: fingerprint}, false
+}
+
+func (s *inFlightIdempotencyStore) Complete(string, IdempotentResponse) {}
+
+func (s *inFlightIdempotencyStore) Release(string) {}
+
+func TestIdempotentCreateCanvas(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1alpha1.AddToScheme(scheme))
+
+	logger, _ := logging.NewLogger(logging.DebugLevel, logging.ConsoleFormat)
+	kubeClient := fake.NewClientBuilder().WithScheme(scheme).Build()
+	server, err := NewServer(
+		context.Background(), &Config{Mode: "test", IdempotencyKeyTTL: time.Hour}, logger, kubeClient, nil,
+	)
+	require.NoError(t, err)
+
+	create := func(key, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/api/v1alpha1/canvases", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if key != "" {
+			req.Header.Set(idempotencyKeyHeader, key)
+		}
+		server.router.ServeHTTP(w, req)
+		return w
+	}
+	errorCode := func(t *testing.T, w *httptest.ResponseRecorder) string {
+		var resp dto.ErrorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		return resp.Code
+	}
+
+	t.Run("ReplaysOriginalResponse", func(t *testing.T) {
+		first := create("key-1", `{"name":"first","displayName":"First"}`)
+		require.Equal(t, http.StatusCreated, first.Code)
+		assert.Empty(t, first.Header().Get(idempotentReplayedHeader))
+
+		// The same request, formatted differently.
+		retry := create("key-1", `{ "displayName": "First", "name": "first" }`)
+		assert.Equal(t, http.StatusCreated, retry.Code)
+		assert.Equal(t, "true", retry.Header().Get(idempotentReplayedHeader))
+		assert.Equal(t, first.Body.String(), retry.Body.String())
+		assert.Equal(t, first.Header().Get("Content-Type"), retry.Header().Get("Content-Type"))
+		assert.Equal(t, first.Header().Get("ETag"), retry.Header().Get("ETag"))
+		assert.NotEqual(t, first.Header().Get("X-Request-ID"), retry.Header().Get("X-Request-ID"))
+
+		list := &v1alpha1.CanvasList{}
+		require.NoError(t, kubeClient.List(context.Background(), list))
+		assert.Len(t, list.Items, 1)
+	})
+
+	t.Run("KeyReusedForDifferentRequest", func(t *testing.T) {
+		w := create("key-1", `{"name":"other","displayName":"Other"}`)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		assert.Equal(t, "IDEMPOTENCY_KEY_REUSED", errorCode(t, w))
+	})
+
+	t.Run("FailuresAreNotRecorded", func(t *testing.T) {
+		w := create("key-2", `{"name":"first","displayName":"First"}`)
+		assert.Equal(t, http.StatusConflict, w.Code)
+
+		require.NoError(t, kubeClient.Delete(context.Background(), &v1alpha1.Canvas{
+			ObjectMeta: metav1.ObjectMeta{Name: "first"},
+		}))
+		w = create("key-2", `{"name":"first","displayName":"First"}`)
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Empty(t, w.Header().Get(idempotentReplayedHeader))
+	})
+
+	t.Run("WithoutKey", func(t *testing.T) {
+		assert.Equal(t, http.StatusCreated, create("", `{"name":"second","displayName":"Second"}`).Code)
+		assert.Equal(t, http.StatusConflict, create("", `{"name":"second","displayName":"Second"}`).Code)
+	})
+
+	t.Run("KeyTooLong", func(t *testing.T) {
+		w := create(strings.Repeat("k", maxIdempotencyKeyLength+1), `{"name":"third","displayName":"Third"}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, "INVALID_IDEMPOTENCY_KEY", errorCode(t, w))
+	})
+
+	t.Run("RequestInFlight", func(t *testing.T) {
+		store := server.idempotencyStore
+		t.Cleanup(func() { server.idempotencyStore = store })
+		server.idempotencyStore = &inFlightIdempotencyStore{}
+
+		w := create("key-3", `{"name":"third","displayName":"Third"}`)
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Equal(t, "IDEMPOTENCY_KEY_IN_USE", errorCode(t, w))
+		assert.Equal(t, "1", w.Header().Get("Retry-After"))
+	})
+}
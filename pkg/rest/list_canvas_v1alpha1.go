@@ -5,36 +5,86 @@ import (
 
 	"github.com/gin-gonic/gin"
 	orrayv1alpha1 "github.com/orray-proj/orray/api/v1alpha1"
+	"github.com/orray-proj/orray/pkg/api"
 	"github.com/orray-proj/orray/pkg/rest/dto"
+	"k8s.io/apimachinery/pkg/labels"
 )
 
 // @id ListCanvasesV1alpha1
 // @Summary List all canvases
-// @Description List all the canvases the user has access to
+// @Description List all the canvases the user has access to. With watch=true
+// @Description the response is a text/event-stream of ADDED, MODIFIED and
+// @Description DELETED events whose data is a Canvas and whose id is the
+// @Description resource version to resume from.
 // @Tags Canvas
-// @Produce json
+// @Produce json,text/event-stream
 // @Param pagination query dto.PaginationRequest false "Pagination parameters"
+// @Param filter query dto.CanvasFilterRequest false "Filter and sort parameters"
+// @Param watch query dto.WatchRequest false "Watch parameters"
 // @Success 200 {object} dto.ListResponse[dto.Canvas]
-// @Failure 400 {object} dto.ErrorResponse "Bad Request"
+// @Failure 400 {object} dto.ValidationErrorResponse "Bad Request"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 403 {object} dto.ErrorResponse "Forbidden"
+// @Failure 410 {object} dto.ErrorResponse "Gone"
+// @Failure 429 {object} dto.ErrorResponse "Too Many Requests"
 // @Failure 500 {object} dto.ErrorResponse "Internal Server Error"
+// @Security BearerAuth
 // @Router /v1alpha1/canvases [get]
 func (s *Server) listCanvasesV1alpha1(c *gin.Context) {
+	var watchReq dto.WatchRequest
+	if err := c.ShouldBindQuery(&watchReq); err != nil {
+		QueryValidationError(c, err)
+		return
+	}
+	if watchReq.Watch {
+		s.watchCanvasesV1alpha1(c, watchReq)
+		return
+	}
+
 	var req dto.PaginationRequest
 	if err := c.ShouldBindQuery(&req); err != nil {
-		s.logger.Error(err, "failed to bind pagination query")
-		ValidationError(c, err)
+		s.requestLogger(c).Debug("failed to bind pagination query", "error", err.Error())
+		QueryValidationError(c, err)
+		return
+	}
+
+	var filter dto.CanvasFilterRequest
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		QueryValidationError(c, err)
 		return
 	}
 
-	canvases, err := s.canvasService.List(c.Request.Context())
+	opts := api.CanvasListOptions{
+		Query: filter.Q,
+		Phase: orrayv1alpha1.CanvasPhase(filter.Phase),
+		Sort:  api.CanvasSort(filter.Sort),
+	}
+	if filter.LabelSelector != "" {
+		// Already validated by the labelselector binding tag.
+		opts.LabelSelector, _ = labels.Parse(filter.LabelSelector)
+	}
+	if opts.FiltersItems() {
+		// Filters and sorting need the whole collection, so a cursor into a
+		// server-side page cannot be honoured.
+		if req.Continue != "" {
+			BadRequest(c, "VALIDATION_ERROR", "Validation failed", []dto.FieldError{{
+				Field:   "continue",
+				Message: "continue cannot be combined with q, phase or sort",
+			}})
+			return
+		}
+	} else {
+		opts.ListOptions = listOptions(req)
+	}
+
+	canvases, err := s.canvasService.List(c.Request.Context(), opts)
 	if err != nil {
-		s.logger.Error(err, "failed to list canvases")
-		InternalServerError(c, err, "failed to list canvases")
+		s.logKubernetesError(c, err, "failed to list canvases")
+		KubernetesError(c, err, "failed to list canvases")
 		return
 	}
 
-	// Paginate the results in-memory and map to DTOs
-	resp := dto.Paginate(canvases.Items, req, func(c orrayv1alpha1.Canvas) dto.Canvas {
+	resp := dto.PaginateList(canvases.Items, canvases.ListMeta, req, func(c orrayv1alpha1.Canvas) dto.Canvas {
 		return dto.CanvasFromV1Alpha1(&c)
 	})
 
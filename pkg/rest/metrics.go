@@ -0,0 +1,105 @@
+package rest
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	restclient "k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	metricsNamespace = "orray"
+	metricsSubsystem = "apiserver"
+
+	// unmatchedRoute labels requests that matched no route, such as those
+	// served by the UI, so raw paths never become label values.
+	unmatchedRoute = "unmatched"
+)
+
+var (
+	httpRequestsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Subsystem: metricsSubsystem,
+			Name:      "http_requests_total",
+			Help:      "Number of HTTP requests, partitioned by route, method and status code.",
+		},
+		[]string{"route", "method", "code"},
+	)
+	httpRequestDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Subsystem: metricsSubsystem,
+			Name:      "http_request_duration_seconds",
+			Help:      "Latency of HTTP requests, partitioned by route, method and status code.",
+			Buckets:   prometheus.DefBuckets,
+		},
+		[]string{"route", "method", "code"},
+	)
+	httpRequestsInFlight = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Subsystem: metricsSubsystem,
+			Name:      "http_requests_in_flight",
+			Help:      "Number of HTTP requests being served, partitioned by route and method.",
+		},
+		[]string{"route", "method"},
+	)
+	kubernetesRequestDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Subsystem: metricsSubsystem,
+			Name:      "kubernetes_request_duration_seconds",
+			Help:      "Latency of requests to the Kubernetes API, partitioned by method and status code.",
+			Buckets:   prometheus.DefBuckets,
+		},
+		[]string{"method", "code"},
+	)
+)
+
+func init() {
+	// The controller-runtime registry also exposes the Go runtime, process
+	// and client-go metrics.
+	metrics.Registry.MustRegister(
+		httpRequestsTotal,
+		httpRequestDuration,
+		httpRequestsInFlight,
+		kubernetesRequestDuration,
+	)
+}
+
+// instrument returns middleware that records request metrics labelled by
+// route template rather than raw path.
+func instrument() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		method := c.Request.Method
+
+		inFlight := httpRequestsInFlight.WithLabelValues(route, method)
+		inFlight.Inc()
+		defer inFlight.Dec()
+
+		start := time.Now()
+		c.Next()
+
+		code := strconv.Itoa(c.Writer.Status())
+		httpRequestsTotal.WithLabelValues(route, method, code).Inc()
+		httpRequestDuration.WithLabelValues(route, method, code).Observe(time.Since(start).Seconds())
+	}
+}
+
+// InstrumentKubernetesConfig records the latency of every request made to
+// the Kubernetes API by clients created from cfg.
+func InstrumentKubernetesConfig(cfg *restclient.Config) {
+	cfg.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+		return promhttp.InstrumentRoundTripperDuration(kubernetesRequestDuration, rt)
+	})
+}
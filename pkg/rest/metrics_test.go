@@ -0,0 +1,34 @@
+package rest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInstrument(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(instrument())
+	router.GET("/canvases/:name", func(c *gin.Context) {
+		assert.Equal(t, 1.0, testutil.ToFloat64(httpRequestsInFlight.WithLabelValues("/canvases/:name", http.MethodGet)))
+		c.Status(http.StatusOK)
+	})
+
+	requests := httpRequestsTotal.WithLabelValues("/canvases/:name", http.MethodGet, "200")
+	unmatched := httpRequestsTotal.WithLabelValues(unmatchedRoute, http.MethodGet, "404")
+	before, beforeUnmatched := testutil.ToFloat64(requests), testutil.ToFloat64(unmatched)
+
+	for _, path := range []string{"/canvases/a", "/canvases/b", "/unknown"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	assert.Equal(t, before+2, testutil.ToFloat64(requests))
+	assert.Equal(t, beforeUnmatched+1, testutil.ToFloat64(unmatched))
+	assert.Equal(t, 0.0, testutil.ToFloat64(httpRequestsInFlight.WithLabelValues("/canvases/:name", http.MethodGet)))
+}
@@ -1,17 +1,41 @@
 package rest
 
 import (
+	"net/http"
+
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	_ "github.com/orray-proj/orray/api/docs"
+	"github.com/orray-proj/orray/pkg/logging"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"go.opentelemetry.io/otel/trace"
 )
 
-// requestID returns middleware that adds a unique ID to each request.
+// tracingService is the name under which the spans of the server are
+// reported.
+const tracingService = "orray-apiserver"
+
+// tracing returns middleware that starts a span named after the matched route
+// for each request, continuing the W3C trace context sent by the client.
+func tracing() gin.HandlerFunc {
+	return otelgin.Middleware(tracingService, otelgin.WithFilter(func(r *http.Request) bool {
+		// Probes would otherwise flood the traces.
+		return r.URL.Path != "/healthz" && r.URL.Path != "/readyz"
+	}))
+}
+
+// requestID returns middleware that adds a unique ID to each request. Unless
+// the client provided one, the ID is the ID of the request's trace, so that
+// it can be looked up in the tracing backend.
 func requestID() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		reqID := c.GetHeader("X-Request-ID")
 		if reqID == "" {
-			reqID = uuid.New().String()
+			if spanContext := trace.SpanContextFromContext(c.Request.Context()); spanContext.HasTraceID() {
+				reqID = spanContext.TraceID().String()
+			} else {
+				reqID = uuid.New().String()
+			}
 		}
 		c.Set("requestId", reqID)
 		c.Header("X-Request-ID", reqID)
@@ -30,3 +54,9 @@ func securityHeaders() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// requestLogger returns the server logger annotated with the request ID and
+// the trace context of the request.
+func (s *Server) requestLogger(c *gin.Context) *logging.Logger {
+	return s.logger.WithContext(c.Request.Context()).WithValues("requestId", c.GetString("requestId"))
+}
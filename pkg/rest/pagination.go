@@ -0,0 +1,18 @@
+package rest
+
+import (
+	"github.com/orray-proj/orray/pkg/api"
+	"github.com/orray-proj/orray/pkg/rest/dto"
+)
+
+// listOptions returns the options to fetch the page described by req. Offset
+// requests need the whole collection, so they are not limited.
+func listOptions(req dto.PaginationRequest) api.ListOptions {
+	if !req.UsesCursor() {
+		return api.ListOptions{}
+	}
+	return api.ListOptions{
+		Limit:    int64(req.Limit),
+		Continue: req.Continue,
+	}
+}
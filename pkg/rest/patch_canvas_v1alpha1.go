@@ -0,0 +1,58 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/orray-proj/orray/pkg/rest/dto"
+)
+
+// @id PatchCanvasV1alpha1
+// @Summary Update a canvas
+// @Description Partially update an existing canvas, leaving omitted fields unchanged
+// @Tags Canvas
+// @Accept json
+// @Produce json
+// @Param name path string true "Canvas name"
+// @Param If-Match header string false "ETags the canvas must still match one of for the change to apply, or *"
+// @Param canvas body dto.PatchCanvasRequest true "Fields to update"
+// @Success 200 {object} dto.Canvas
+// @Header 200 {string} ETag "Resource version of the canvas"
+// @Failure 400 {object} dto.ValidationErrorResponse "Bad Request"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 403 {object} dto.ErrorResponse "Forbidden"
+// @Failure 404 {object} dto.ErrorResponse "Not Found"
+// @Failure 409 {object} dto.ErrorResponse "Conflict"
+// @Failure 412 {object} dto.ErrorResponse "Precondition Failed"
+// @Failure 413 {object} dto.ErrorResponse "Request Entity Too Large"
+// @Failure 422 {object} dto.ValidationErrorResponse "Unprocessable Entity"
+// @Failure 429 {object} dto.ErrorResponse "Too Many Requests"
+// @Failure 500 {object} dto.ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /v1alpha1/canvases/{name} [patch]
+func (s *Server) patchCanvasV1alpha1(c *gin.Context) {
+	name := c.Param("name")
+
+	var req dto.PatchCanvasRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ValidationError(c, err)
+		return
+	}
+
+	preconditions, ok := ifMatchPreconditions(c)
+	if !ok {
+		return
+	}
+
+	canvas, err := s.canvasService.Patch(c.Request.Context(), name, req.DisplayName, preconditions)
+	if err != nil {
+		if preconditionFailed(c, err) {
+			return
+		}
+		s.logKubernetesError(c, err, "failed to patch canvas", "name", name)
+		KubernetesError(c, err, "failed to patch canvas")
+		return
+	}
+
+	writeCanvas(c, http.StatusOK, dto.CanvasFromV1Alpha1(canvas))
+}
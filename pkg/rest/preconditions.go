@@ -0,0 +1,104 @@
+package rest
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/orray-proj/orray/pkg/api"
+	"github.com/orray-proj/orray/pkg/rest/dto"
+)
+
+// canvasETag returns the entity tag of a canvas. The resource version changes
+// on every write, so it identifies the representation of the canvas.
+func canvasETag(canvas dto.Canvas) string {
+	return `"` + canvas.ResourceVersion + `"`
+}
+
+// writeCanvas responds with canvas and its entity tag.
+func writeCanvas(c *gin.Context, status int, canvas dto.Canvas) {
+	c.Header("ETag", canvasETag(canvas))
+	c.JSON(status, canvas)
+}
+
+// parseETags splits the value of an If-Match or If-None-Match header into its
+// entity tags, keeping their quotes and weakness prefix.
+func parseETags(value string) []string {
+	var tags []string
+	for value = strings.TrimSpace(value); value != ""; value = strings.TrimLeft(value, ", \t") {
+		start := 0
+		if strings.HasPrefix(value, "W/") {
+			start = len("W/")
+		}
+		if !strings.HasPrefix(value[start:], `"`) {
+			// An unquoted value, such as "*", runs until the next comma.
+			tag, rest, _ := strings.Cut(value, ",")
+			tags = append(tags, strings.TrimSpace(tag))
+			value = rest
+			continue
+		}
+		end := strings.IndexByte(value[start+1:], '"')
+		if end < 0 {
+			return append(tags, value)
+		}
+		end += start + 2
+		tags = append(tags, value[:end])
+		value = value[end:]
+	}
+	return tags
+}
+
+// ifMatchPreconditions turns the If-Match header of the request into
+// preconditions on the canvas it changes. The change applies if the canvas
+// exists and, unless the header is "*", its entity tag is one of those listed.
+// It responds and returns false if the header cannot be honoured.
+func ifMatchPreconditions(c *gin.Context) (api.Preconditions, bool) {
+	value := c.GetHeader("If-Match")
+	if value == "" {
+		return api.Preconditions{}, true
+	}
+
+	tags := parseETags(value)
+	if len(tags) == 1 && tags[0] == "*" {
+		return api.Preconditions{MustExist: true}, true
+	}
+	preconditions := api.Preconditions{MustExist: true}
+	for _, tag := range tags {
+		// If-Match uses the strong comparison, which weak tags never satisfy.
+		if strings.HasPrefix(tag, "W/") {
+			continue
+		}
+		if len(tag) < 2 || !strings.HasPrefix(tag, `"`) || !strings.HasSuffix(tag, `"`) {
+			BadRequest(c, "INVALID_PRECONDITION", "If-Match must hold \"*\" or a list of quoted entity tags", nil)
+			return api.Preconditions{}, false
+		}
+		preconditions.ResourceVersions = append(preconditions.ResourceVersions, strings.Trim(tag, `"`))
+	}
+	if len(preconditions.ResourceVersions) == 0 {
+		PreconditionFailed(c, "If-Match does not accept weak entity tags")
+		return api.Preconditions{}, false
+	}
+	return preconditions, true
+}
+
+// ifNoneMatch reports whether the If-None-Match header of the request matches
+// etag, in which case the client's copy is current.
+func ifNoneMatch(c *gin.Context, etag string) bool {
+	for _, tag := range parseETags(c.GetHeader("If-None-Match")) {
+		// If-None-Match uses the weak comparison.
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// preconditionFailed responds with a 412 status code and returns true if err
+// reports a failed precondition.
+func preconditionFailed(c *gin.Context, err error) bool {
+	if !errors.Is(err, api.ErrPreconditionFailed) {
+		return false
+	}
+	PreconditionFailed(c, "The canvas was modified or deleted since it was read")
+	return true
+}
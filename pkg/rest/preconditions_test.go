@@ -0,0 +1,174 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/orray-proj/orray/api/v1alpha1"
+	"github.com/orray-proj/orray/pkg/logging"
+	"github.com/orray-proj/orray/pkg/rest/dto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestParseETags(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected []string
+	}{
+		{value: "", expected: nil},
+		{value: "*", expected: []string{"*"}},
+		{value: `"1"`, expected: []string{`"1"`}},
+		{value: `W/"1"`, expected: []string{`W/"1"`}},
+		{value: ` "1", W/"2" ,"3"`, expected: []string{`"1"`, `W/"2"`, `"3"`}},
+		{value: `"a,b", "c"`, expected: []string{`"a,b"`, `"c"`}},
+		{value: `1, 2`, expected: []string{"1", "2"}},
+		{value: `"1`, expected: []string{`"1`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			assert.Equal(t, tt.expected, parseETags(tt.value))
+		})
+	}
+}
+
+func TestCanvasPreconditions(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1alpha1.AddToScheme(scheme))
+
+	logger, _ := logging.NewLogger(logging.DebugLevel, logging.ConsoleFormat)
+	kubeClient := fake.NewClientBuilder().WithScheme(scheme).Build()
+	server, err := NewServer(context.Background(), &Config{Mode: "test"}, logger, kubeClient, nil)
+	require.NoError(t, err)
+
+	send := func(method, path string, body io.Reader, header http.Header) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, body)
+		req.Header = header
+		req.Header.Set("Content-Type", "application/json")
+		server.router.ServeHTTP(w, req)
+		return w
+	}
+	errorCode := func(t *testing.T, w *httptest.ResponseRecorder) string {
+		var resp dto.ErrorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		return resp.Code
+	}
+
+	w := send(http.MethodPost, "/api/v1alpha1/canvases",
+		strings.NewReader(`{"name":"test","displayName":"Test"}`), http.Header{})
+	require.Equal(t, http.StatusCreated, w.Code)
+	etag := w.Header().Get("ETag")
+	var created dto.Canvas
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.Equal(t, `"`+created.ResourceVersion+`"`, etag)
+
+	t.Run("GetReturnsETag", func(t *testing.T) {
+		w := send(http.MethodGet, "/api/v1alpha1/canvases/test", nil, http.Header{})
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, etag, w.Header().Get("ETag"))
+	})
+
+	t.Run("GetNotModified", func(t *testing.T) {
+		for _, value := range []string{etag, "W/" + etag, `"0", ` + etag, "*"} {
+			w := send(http.MethodGet, "/api/v1alpha1/canvases/test", nil, http.Header{"If-None-Match": {value}})
+			assert.Equal(t, http.StatusNotModified, w.Code, value)
+			assert.Equal(t, etag, w.Header().Get("ETag"))
+			assert.Empty(t, w.Body.String())
+		}
+
+		w := send(http.MethodGet, "/api/v1alpha1/canvases/test", nil, http.Header{"If-None-Match": {`"0"`}})
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("StaleIfMatch", func(t *testing.T) {
+		header := http.Header{"If-Match": {`"0"`}}
+		tests := []struct {
+			method string
+			body   string
+		}{
+			{method: http.MethodPut, body: `{"displayName":"Stale"}`},
+			{method: http.MethodPatch, body: `{"displayName":"Stale"}`},
+			{method: http.MethodDelete},
+		}
+		for _, tt := range tests {
+			w := send(tt.method, "/api/v1alpha1/canvases/test", strings.NewReader(tt.body), header.Clone())
+			assert.Equal(t, http.StatusPreconditionFailed, w.Code, tt.method)
+			assert.Equal(t, "PRECONDITION_FAILED", errorCode(t, w))
+		}
+
+		canvas := &v1alpha1.Canvas{}
+		require.NoError(t, kubeClient.Get(context.Background(), client.ObjectKey{Name: "test"}, canvas))
+		assert.Equal(t, "Test", canvas.Spec.DisplayName)
+	})
+
+	t.Run("WeakIfMatch", func(t *testing.T) {
+		w := send(http.MethodPut, "/api/v1alpha1/canvases/test",
+			strings.NewReader(`{"displayName":"Weak"}`), http.Header{"If-Match": {"W/" + etag}})
+		assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	})
+
+	t.Run("InvalidIfMatch", func(t *testing.T) {
+		for _, value := range []string{"42", `"0", 42`} {
+			w := send(http.MethodPut, "/api/v1alpha1/canvases/test",
+				strings.NewReader(`{"displayName":"Invalid"}`), http.Header{"If-Match": {value}})
+			assert.Equal(t, http.StatusBadRequest, w.Code, value)
+			assert.Equal(t, "INVALID_PRECONDITION", errorCode(t, w))
+		}
+	})
+
+	t.Run("CurrentIfMatch", func(t *testing.T) {
+		// Any of the listed entity tags may match.
+		w := send(http.MethodPut, "/api/v1alpha1/canvases/test",
+			strings.NewReader(`{"displayName":"Updated"}`), http.Header{"If-Match": {`"0", W/"1", ` + etag}})
+		require.Equal(t, http.StatusOK, w.Code)
+		updated := w.Header().Get("ETag")
+		assert.NotEqual(t, etag, updated)
+
+		// The previous entity tag is now stale.
+		w = send(http.MethodPatch, "/api/v1alpha1/canvases/test",
+			strings.NewReader(`{"displayName":"Patched"}`), http.Header{"If-Match": {etag}})
+		assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+
+		w = send(http.MethodPatch, "/api/v1alpha1/canvases/test",
+			strings.NewReader(`{"displayName":"Patched"}`), http.Header{"If-Match": {updated}})
+		require.Equal(t, http.StatusOK, w.Code)
+		patched := w.Header().Get("ETag")
+
+		w = send(http.MethodDelete, "/api/v1alpha1/canvases/test", nil, http.Header{"If-Match": {patched}})
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	})
+
+	t.Run("WildcardIfMatch", func(t *testing.T) {
+		w := send(http.MethodPost, "/api/v1alpha1/canvases",
+			strings.NewReader(`{"name":"other","displayName":"Other"}`), http.Header{})
+		require.Equal(t, http.StatusCreated, w.Code)
+
+		w = send(http.MethodDelete, "/api/v1alpha1/canvases/other", nil, http.Header{"If-Match": {"*"}})
+		assert.Equal(t, http.StatusNoContent, w.Code)
+
+		// The wildcard requires the canvas to exist.
+		tests := []struct {
+			method string
+			body   string
+		}{
+			{method: http.MethodPut, body: `{"displayName":"Other"}`},
+			{method: http.MethodPatch, body: `{"displayName":"Other"}`},
+			{method: http.MethodDelete},
+		}
+		for _, tt := range tests {
+			w := send(tt.method, "/api/v1alpha1/canvases/other", strings.NewReader(tt.body),
+				http.Header{"If-Match": {"*"}})
+			assert.Equal(t, http.StatusPreconditionFailed, w.Code, tt.method)
+			assert.Equal(t, "PRECONDITION_FAILED", errorCode(t, w))
+		}
+	})
+}
@@ -0,0 +1,245 @@
+package rest
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/time/rate"
+)
+
+// rateLimiterIdleTimeout is how long the limiter of a client is kept after its
+// last request. An idle client's bucket is full again long before that.
+const rateLimiterIdleTimeout = 10 * time.Minute
+
+// Route groups sharing a rate limit. Every route belongs to a group named
+// after its resource and verb, which falls back to the read or write group
+// unless it has a limit of its own.
+const (
+	rateLimitGroupRead           = "read"
+	rateLimitGroupWrite          = "write"
+	rateLimitGroupVersionGet     = "version:get"
+	rateLimitGroupCanvasesList   = "canvases:list"
+	rateLimitGroupCanvasesGet    = "canvases:get"
+	rateLimitGroupCanvasesCreate = "canvases:create"
+	rateLimitGroupCanvasesUpdate = "canvases:update"
+	rateLimitGroupCanvasesDelete = "canvases:delete"
+)
+
+// RateLimitConfig configures the token buckets limiting how many requests each
+// client may send to the API. Clients are identified by their authenticated
+// username, or by their IP address when authentication is disabled. A zero or
+// negative rate disables the limit of its route group.
+type RateLimitConfig struct {
+	// IPRequestsPerSecond and IPBurst limit all the requests from each IP
+	// address. They are checked before authentication, so that invalid
+	// tokens cannot be used to flood the authenticator.
+	IPRequestsPerSecond float64 `env:"IP_REQUESTS_PER_SECOND" envDefault:"50"`
+	IPBurst             int     `env:"IP_BURST" envDefault:"100"`
+	// Groups limits the requests to each route group. The read group covers
+	// the safe requests (GET and HEAD), including watches, and the write group
+	// the others, unless their route group has a limit of its own. Canvas
+	// creations are limited separately since each of them provisions a
+	// namespace.
+	Groups RateLimits `env:"GROUPS" envDefault:"read=20/40,write=1/10,canvases:create=0.2/5"`
+}
+
+// RateLimit is the token bucket of a route group.
+type RateLimit struct {
+	RequestsPerSecond float64
+	Burst             int
+}
+
+// RateLimits maps route groups to their limits. It is parsed from a
+// comma-separated list of group=requestsPerSecond/burst entries.
+type RateLimits map[string]RateLimit
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (l *RateLimits) UnmarshalText(text []byte) error {
+	limits := RateLimits{}
+	for entry := range strings.SplitSeq(string(text), ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		group, limit, ok := strings.Cut(entry, "=")
+		if !ok {
+			return fmt.Errorf("invalid rate limit %q: expected group=requestsPerSecond/burst", entry)
+		}
+		rps, burst, ok := strings.Cut(limit, "/")
+		if !ok {
+			return fmt.Errorf("invalid rate limit %q: expected group=requestsPerSecond/burst", entry)
+		}
+		requestsPerSecond, err := strconv.ParseFloat(rps, 64)
+		if err != nil {
+			return fmt.Errorf("invalid requests per second in rate limit %q: %w", entry, err)
+		}
+		b, err := strconv.Atoi(burst)
+		if err != nil {
+			return fmt.Errorf("invalid burst in rate limit %q: %w", entry, err)
+		}
+		limits[group] = RateLimit{RequestsPerSecond: requestsPerSecond, Burst: b}
+	}
+	*l = limits
+	return nil
+}
+
+// clientRateLimiter is the token bucket of a single client.
+type clientRateLimiter struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// rateLimiter holds one token bucket per client for a route group.
+type rateLimiter struct {
+	limit rate.Limit
+	burst int
+	now   func() time.Time
+
+	mu        sync.Mutex
+	clients   map[string]*clientRateLimiter
+	lastSweep time.Time
+}
+
+func newRateLimiter(requestsPerSecond float64, burst int) *rateLimiter {
+	limit := rate.Limit(requestsPerSecond)
+	if requestsPerSecond <= 0 {
+		limit = rate.Inf
+	}
+	return &rateLimiter{
+		limit:   limit,
+		burst:   max(burst, 1),
+		now:     time.Now,
+		clients: map[string]*clientRateLimiter{},
+	}
+}
+
+// allow takes a token from the bucket of client. If the bucket is empty, it
+// returns false and how long the client should wait before retrying.
+func (l *rateLimiter) allow(client string) (bool, time.Duration) {
+	if l.limit == rate.Inf {
+		return true, 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	entry, ok := l.clients[client]
+	if !ok {
+		entry = &clientRateLimiter{limiter: rate.NewLimiter(l.limit, l.burst)}
+		l.clients[client] = entry
+	}
+	entry.lastSeen = now
+
+	reservation := entry.limiter.ReserveN(now, 1)
+	if delay := reservation.DelayFrom(now); delay > 0 {
+		// The request is rejected, so the token must not be consumed.
+		reservation.CancelAt(now)
+		return false, delay
+	}
+	return true, 0
+}
+
+// sweep forgets the clients that have been idle for a while, so that the
+// number of buckets does not grow without bound.
+func (l *rateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < rateLimiterIdleTimeout {
+		return
+	}
+	l.lastSweep = now
+	for client, entry := range l.clients {
+		if now.Sub(entry.lastSeen) > rateLimiterIdleTimeout {
+			delete(l.clients, client)
+		}
+	}
+}
+
+// ipRateLimit returns middleware that limits the rate of requests of each IP
+// address. It runs before authentication, which may be costly.
+func (s *Server) ipRateLimit() gin.HandlerFunc {
+	limiter := newRateLimiter(s.config.RateLimit.IPRequestsPerSecond, s.config.RateLimit.IPBurst)
+
+	return func(c *gin.Context) {
+		allowed, retryAfter := limiter.allow(c.ClientIP())
+		if !allowed {
+			TooManyRequests(c, retryAfter)
+			return
+		}
+		c.Next()
+	}
+}
+
+// newRateLimiters creates the limiter of each route group of limits.
+func newRateLimiters(limits RateLimits) map[string]*rateLimiter {
+	limiters := make(map[string]*rateLimiter, len(limits))
+	for group, limit := range limits {
+		limiters[group] = newRateLimiter(limit.RequestsPerSecond, limit.Burst)
+	}
+	return limiters
+}
+
+// rateLimit returns middleware that limits the rate of requests of each
+// client to the routes of group. A group without a limit of its own shares
+// the read limiter for safe requests and the write limiter for the others.
+// It must run after authentication so that clients are keyed by username.
+func (s *Server) rateLimit(group string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limiter, ok := s.rateLimiters[group]
+		if !ok {
+			limiter = s.rateLimiters[rateLimitGroupWrite]
+			if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+				limiter = s.rateLimiters[rateLimitGroupRead]
+			}
+		}
+		if limiter == nil {
+			c.Next()
+			return
+		}
+
+		allowed, retryAfter := limiter.allow(rateLimitKey(c))
+		if !allowed {
+			TooManyRequests(c, retryAfter)
+			return
+		}
+		c.Next()
+	}
+}
+
+// rateLimitKey identifies the client of a request.
+func rateLimitKey(c *gin.Context) string {
+	if user, ok := currentUser(c); ok && user.Username != "" {
+		return "user:" + user.Username
+	}
+	return "ip:" + c.ClientIP()
+}
+
+// retryAfterSeconds formats a delay for the Retry-After header, which only
+// accepts whole seconds.
+func retryAfterSeconds(delay time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(delay.Seconds())))
+}
+
+// limitRequestBody returns middleware that rejects request bodies larger than
+// maxBytes. Bodies without a declared length are cut off while being read.
+func limitRequestBody(maxBytes int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if maxBytes <= 0 || c.Request.Body == nil {
+			c.Next()
+			return
+		}
+		if c.Request.ContentLength > maxBytes {
+			RequestEntityTooLarge(c, maxBytes)
+			return
+		}
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes)
+		c.Next()
+	}
+}
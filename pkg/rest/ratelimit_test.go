@@ -0,0 +1,213 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/orray-proj/orray/api/v1alpha1"
+	"github.com/orray-proj/orray/pkg/auth"
+	"github.com/orray-proj/orray/pkg/logging"
+	"github.com/orray-proj/orray/pkg/rest/dto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestRateLimiter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	limiter := newRateLimiter(0.5, 2)
+	limiter.now = func() time.Time { return now }
+
+	t.Run("Burst", func(t *testing.T) {
+		for range 2 {
+			allowed, _ := limiter.allow("alice")
+			assert.True(t, allowed)
+		}
+		allowed, retryAfter := limiter.allow("alice")
+		assert.False(t, allowed)
+		assert.Equal(t, 2*time.Second, retryAfter)
+	})
+
+	t.Run("ClientsAreIndependent", func(t *testing.T) {
+		allowed, _ := limiter.allow("bob")
+		assert.True(t, allowed)
+	})
+
+	t.Run("Refill", func(t *testing.T) {
+		now = now.Add(2 * time.Second)
+		allowed, _ := limiter.allow("alice")
+		assert.True(t, allowed)
+		allowed, _ = limiter.allow("alice")
+		assert.False(t, allowed)
+	})
+
+	t.Run("IdleClientsAreForgotten", func(t *testing.T) {
+		now = now.Add(2 * rateLimiterIdleTimeout)
+		allowed, _ := limiter.allow("carol")
+		assert.True(t, allowed)
+		assert.Len(t, limiter.clients, 1)
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		unlimited := newRateLimiter(0, 0)
+		for range 100 {
+			allowed, _ := unlimited.allow("alice")
+			require.True(t, allowed)
+		}
+	})
+}
+
+func TestRateLimitsUnmarshalText(t *testing.T) {
+	var limits RateLimits
+	require.NoError(t, limits.UnmarshalText([]byte("read=20/40, canvases:create=0.2/5")))
+	assert.Equal(t, RateLimits{
+		"read":            {RequestsPerSecond: 20, Burst: 40},
+		"canvases:create": {RequestsPerSecond: 0.2, Burst: 5},
+	}, limits)
+
+	for _, invalid := range []string{"read", "read=20", "read=fast/40", "read=20/many"} {
+		assert.Error(t, limits.UnmarshalText([]byte(invalid)), invalid)
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1alpha1.AddToScheme(scheme))
+
+	logger, _ := logging.NewLogger(logging.DebugLevel, logging.ConsoleFormat)
+	newServer := func(t *testing.T, cfg *Config) *Server {
+		cfg.Mode = "test"
+		server, err := NewServer(
+			context.Background(), cfg, logger,
+			fake.NewClientBuilder().WithScheme(scheme).Build(), nil,
+		)
+		require.NoError(t, err)
+		return server
+	}
+
+	send := func(server *Server, method, remoteAddr string, body io.Reader) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, "/api/v1alpha1/canvases", body)
+		req.RemoteAddr = remoteAddr
+		req.Header.Set("Content-Type", "application/json")
+		server.router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("CreatesAreLimitedPerClient", func(t *testing.T) {
+		server := newServer(t, &Config{RateLimit: RateLimitConfig{Groups: RateLimits{
+			rateLimitGroupWrite:          {RequestsPerSecond: 0.1, Burst: 1},
+			rateLimitGroupCanvasesCreate: {RequestsPerSecond: 0.1, Burst: 1},
+		}}})
+
+		w := send(server, http.MethodPost, "192.0.2.1:1234", strings.NewReader(`{"name":"first","displayName":"First"}`))
+		assert.Equal(t, http.StatusCreated, w.Code)
+
+		w = send(server, http.MethodPost, "192.0.2.1:1234", strings.NewReader(`{"name":"second","displayName":"Second"}`))
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		assert.Equal(t, "10", w.Header().Get("Retry-After"))
+		var resp dto.ErrorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, "RATE_LIMITED", resp.Code)
+
+		// Reads and other writes have their own bucket, and so do other clients.
+		assert.Equal(t, http.StatusOK, send(server, http.MethodGet, "192.0.2.1:1234", nil).Code)
+		w = httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodDelete, "/api/v1alpha1/canvases/first", nil)
+		req.RemoteAddr = "192.0.2.1:1234"
+		server.router.ServeHTTP(w, req)
+		assert.NotEqual(t, http.StatusTooManyRequests, w.Code)
+		w = send(server, http.MethodPost, "192.0.2.2:1234", strings.NewReader(`{"name":"second","displayName":"Second"}`))
+		assert.Equal(t, http.StatusCreated, w.Code)
+	})
+
+	t.Run("UntrustedForwardedFor", func(t *testing.T) {
+		server := newServer(t, &Config{RateLimit: RateLimitConfig{Groups: RateLimits{
+			rateLimitGroupRead: {RequestsPerSecond: 0.1, Burst: 1},
+		}}})
+
+		for i, expected := range []int{http.StatusOK, http.StatusTooManyRequests} {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/api/v1alpha1/canvases", nil)
+			req.RemoteAddr = "192.0.2.1:1234"
+			req.Header.Set("X-Forwarded-For", []string{"198.51.100.1", "198.51.100.2"}[i])
+			server.router.ServeHTTP(w, req)
+			assert.Equal(t, expected, w.Code)
+		}
+	})
+
+	t.Run("KeyedByUser", func(t *testing.T) {
+		authenticator, err := auth.NewStaticAuthenticator([]string{"alice-1=alice", "alice-2=alice", "bob=bob"})
+		require.NoError(t, err)
+		server := &Server{
+			logger: logger, authenticator: authenticator,
+			rateLimiters: newRateLimiters(RateLimits{rateLimitGroupRead: {RequestsPerSecond: 0.1, Burst: 1}}),
+		}
+		router := gin.New()
+		router.Use(server.authenticate(), server.rateLimit(rateLimitGroupCanvasesGet))
+		router.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+		get := func(token string) int {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			router.ServeHTTP(w, req)
+			return w.Code
+		}
+
+		assert.Equal(t, http.StatusOK, get("alice-1"))
+		assert.Equal(t, http.StatusTooManyRequests, get("alice-2"))
+		assert.Equal(t, http.StatusOK, get("bob"))
+	})
+
+	t.Run("IPLimitedBeforeAuthentication", func(t *testing.T) {
+		authenticator, err := auth.NewStaticAuthenticator([]string{"secret=alice"})
+		require.NoError(t, err)
+		server := &Server{
+			config: &Config{RateLimit: RateLimitConfig{IPRequestsPerSecond: 0.1, IPBurst: 1}},
+			logger: logger, authenticator: authenticator,
+		}
+		router := gin.New()
+		router.Use(server.ipRateLimit(), server.authenticate())
+		router.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+		get := func(remoteAddr, token string) int {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = remoteAddr
+			req.Header.Set("Authorization", "Bearer "+token)
+			router.ServeHTTP(w, req)
+			return w.Code
+		}
+
+		assert.Equal(t, http.StatusUnauthorized, get("192.0.2.1:1234", "wrong"))
+		assert.Equal(t, http.StatusTooManyRequests, get("192.0.2.1:1234", "secret"))
+		assert.Equal(t, http.StatusOK, get("192.0.2.2:1234", "secret"))
+	})
+
+	t.Run("RequestBodyTooLarge", func(t *testing.T) {
+		server := newServer(t, &Config{MaxRequestBodyBytes: 64})
+		body := `{"name":"large","displayName":"` + strings.Repeat("x", 100) + `"}`
+
+		w := send(server, http.MethodPost, "192.0.2.1:1234", strings.NewReader(body))
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+
+		// Without a declared length the body is cut off while being decoded.
+		w = send(server, http.MethodPost, "192.0.2.1:1234", io.MultiReader(strings.NewReader(body)))
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+		var resp dto.ErrorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, "REQUEST_TOO_LARGE", resp.Code)
+
+		w = send(server, http.MethodPost, "192.0.2.1:1234", strings.NewReader(`{"name":"small","displayName":"Small"}`))
+		assert.Equal(t, http.StatusCreated, w.Code)
+	})
+}
@@ -1,8 +1,14 @@
 package rest
 
 import (
+	"fmt"
+	"maps"
+	"net/http"
+	"strings"
+
 	"github.com/gin-gonic/gin"
 	_ "github.com/orray-proj/orray/api/docs"
+	"github.com/orray-proj/orray/pkg/ui"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
@@ -22,19 +28,76 @@ import (
 // @host localhost:8080
 // @BasePath /api
 
-func (s *Server) setupRESTRouter() {
-	router := gin.Default()
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description Bearer token, formatted as "Bearer <token>".
+
+func (s *Server) setupRESTRouter() error {
+	registerValidators()
+
+	router := gin.New()
+	if err := router.SetTrustedProxies(s.config.TrustedProxies); err != nil {
+		return fmt.Errorf("invalid trusted proxies: %w", err)
+	}
+	router.Use(instrument())
+	router.Use(tracing())
+	router.Use(gin.LoggerWithConfig(gin.LoggerConfig{
+		// Probes would otherwise flood the access log.
+		SkipPaths: []string{"/healthz", "/readyz"},
+	}))
+	router.Use(gin.Recovery())
 
 	router.Use(requestID())
 	router.Use(securityHeaders())
+	router.Use(limitRequestBody(s.config.MaxRequestBodyBytes))
+
+	liveness := healthHandler("/healthz", livenessChecks())
+	router.GET("/healthz", liveness)
+	router.GET("/healthz/*check", liveness)
+	readyChecks := livenessChecks()
+	if s.clientset != nil {
+		maps.Copy(readyChecks, readinessChecks(s.clientset.Discovery()))
+	}
+	readiness := healthHandler("/readyz", readyChecks)
+	router.GET("/readyz", readiness)
+	router.GET("/readyz/*check", readiness)
 
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 	api := router.Group("/api")
+	api.Use(s.audit())
+	api.Use(s.ipRateLimit())
+	api.Use(s.authenticate())
+	api.GET("/version", s.rateLimit(rateLimitGroupVersionGet), s.getVersion)
 	v1alpha1 := api.Group("/v1alpha1")
 	{
-		v1alpha1.GET("/canvases", s.listCanvasesV1alpha1)
-		v1alpha1.POST("/canvases", s.createCanvasV1alpha1)
+		v1alpha1.GET("/canvases", s.rateLimit(rateLimitGroupCanvasesList), s.listCanvasesV1alpha1)
+		v1alpha1.POST("/canvases", s.rateLimit(rateLimitGroupCanvasesCreate), s.createCanvasV1alpha1)
+		v1alpha1.GET("/canvases/:name", s.rateLimit(rateLimitGroupCanvasesGet), s.getCanvasV1alpha1)
+		v1alpha1.PUT("/canvases/:name", s.rateLimit(rateLimitGroupCanvasesUpdate), s.updateCanvasV1alpha1)
+		v1alpha1.PATCH("/canvases/:name", s.rateLimit(rateLimitGroupCanvasesUpdate), s.patchCanvasV1alpha1)
+		v1alpha1.DELETE("/canvases/:name", s.rateLimit(rateLimitGroupCanvasesDelete), s.deleteCanvasV1alpha1)
 	}
 
+	router.NoRoute(serveUI(ui.Handler()))
+
 	s.router = router
+	return nil
+}
+
+// serveUI returns a handler serving the embedded UI for every path that no
+// route matched. Unknown API and swagger paths get a JSON 404 instead of the
+// UI's index page.
+func serveUI(uiHandler http.Handler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		for _, prefix := range []string{"/api", "/swagger"} {
+			if c.Request.URL.Path == prefix || strings.HasPrefix(c.Request.URL.Path, prefix+"/") {
+				NotFound(c, "")
+				return
+			}
+		}
+		// gin presets the status of unmatched requests to 404.
+		c.Status(http.StatusOK)
+		uiHandler.ServeHTTP(c.Writer, c.Request)
+	}
 }
@@ -0,0 +1,46 @@
+package rest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServeUI(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.NoRoute(serveUI(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("ui"))
+	})))
+
+	tests := []struct {
+		path           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{path: "/", expectedStatus: http.StatusOK, expectedBody: "ui"},
+		{path: "/canvases/test", expectedStatus: http.StatusOK, expectedBody: "ui"},
+		{path: "/apis", expectedStatus: http.StatusOK, expectedBody: "ui"},
+		{path: "/api", expectedStatus: http.StatusNotFound},
+		{path: "/api/v1alpha1/unknown", expectedStatus: http.StatusNotFound},
+		{path: "/swagger/unknown", expectedStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedBody != "" {
+				assert.Equal(t, tt.expectedBody, w.Body.String())
+			} else {
+				assert.Contains(t, w.Body.String(), "NOT_FOUND")
+			}
+		})
+	}
+}
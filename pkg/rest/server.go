@@ -3,15 +3,19 @@ package rest
 import (
 	"context"
 	"fmt"
+	"net"
 	"net/http"
+	"time"
 
 	"github.com/caarlos0/env/v11"
 	"github.com/gin-gonic/gin"
 	"github.com/orray-proj/orray/pkg/api"
+	"github.com/orray-proj/orray/pkg/auth"
 	"github.com/orray-proj/orray/pkg/logging"
 	basesrv "github.com/orray-proj/orray/pkg/server"
 	"k8s.io/client-go/kubernetes"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 )
 
 // Config extends the base server config with REST-specific options.
@@ -20,6 +24,47 @@ type Config struct {
 
 	BindAddress string `env:"REST_BIND_ADDRESS" envDefault:":8080"`
 	Mode        string `env:"REST_MODE" envDefault:"release"`
+
+	// WatchHeartbeatInterval is how often an idle watch stream sends a
+	// heartbeat to keep intermediaries from closing the connection.
+	WatchHeartbeatInterval time.Duration `env:"REST_WATCH_HEARTBEAT_INTERVAL" envDefault:"15s"`
+
+	// ShutdownTimeout is how long in-flight requests are given to complete
+	// when the server stops.
+	ShutdownTimeout time.Duration `env:"REST_SHUTDOWN_TIMEOUT" envDefault:"25s"`
+	// ReadHeaderTimeout, ReadTimeout, WriteTimeout and IdleTimeout bound the
+	// time spent on a connection, see net/http.Server. Watch streams are
+	// exempt from the write timeout.
+	ReadHeaderTimeout time.Duration `env:"REST_READ_HEADER_TIMEOUT" envDefault:"10s"`
+	ReadTimeout       time.Duration `env:"REST_READ_TIMEOUT" envDefault:"30s"`
+	WriteTimeout      time.Duration `env:"REST_WRITE_TIMEOUT" envDefault:"60s"`
+	IdleTimeout       time.Duration `env:"REST_IDLE_TIMEOUT" envDefault:"120s"`
+	// MaxHeaderBytes limits the size of request headers.
+	MaxHeaderBytes int `env:"REST_MAX_HEADER_BYTES" envDefault:"1048576"`
+	// MaxRequestBodyBytes limits the size of request bodies. A zero or
+	// negative value disables the limit.
+	MaxRequestBodyBytes int64 `env:"REST_MAX_REQUEST_BODY_BYTES" envDefault:"1048576"`
+	// TrustedProxies lists the addresses or CIDRs of the proxies whose
+	// X-Forwarded-For header is trusted to identify clients. By default the
+	// address of the connection is used.
+	TrustedProxies []string `env:"REST_TRUSTED_PROXIES"`
+
+	// RateLimit limits how many requests each client may send.
+	RateLimit RateLimitConfig `envPrefix:"REST_RATE_LIMIT_"`
+
+	// IdempotencyKeyTTL is how long the response to a request sent with an
+	// Idempotency-Key is kept for replay. A zero value disables idempotency
+	// keys.
+	IdempotencyKeyTTL time.Duration `env:"REST_IDEMPOTENCY_KEY_TTL" envDefault:"24h"`
+
+	// Audit configures the audit log of the requests changing canvases.
+	Audit AuditConfig `envPrefix:"REST_AUDIT_"`
+
+	// TLS configures serving over TLS, with optional client certificates.
+	TLS TLSConfig `envPrefix:"REST_TLS_"`
+
+	// Auth configures how API clients are authenticated.
+	Auth auth.Config `envPrefix:"REST_AUTH_"`
 }
 
 // NewConfig create a new config for a rest server
@@ -37,50 +82,155 @@ type Server struct {
 	logger *logging.Logger
 	router *gin.Engine
 
-	kubeClient client.Client
+	kubeClient client.WithWatch
 	clientset  kubernetes.Interface
 
-	canvasService api.CanvasService
+	authenticator    auth.Authenticator
+	canvasService    api.CanvasService
+	idempotencyStore IdempotencyStore
+	auditor          *auditor
+	rateLimiters     map[string]*rateLimiter
+
+	// shutdown is closed when the server starts shutting down, so that
+	// long-lived watch streams end instead of holding the drain up.
+	shutdown chan struct{}
 }
 
 // NewServer creates a new REST API server.
 func NewServer(
 	ctx context.Context, cfg *Config, logger *logging.Logger,
-	kubeClient client.Client, clientset kubernetes.Interface,
-) *Server {
+	kubeClient client.WithWatch, clientset kubernetes.Interface,
+) (*Server, error) {
 	if cfg.Mode == "release" {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
+	authenticator, err := auth.NewAuthenticator(ctx, cfg.Auth, clientset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create authenticator: %w", err)
+	}
+
+	authorizer, err := auth.NewAuthorizer(cfg.Auth, clientset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create authorizer: %w", err)
+	}
+
+	canvasService := api.NewInstrumentedCanvasService(api.NewCanvasService(kubeClient))
+	if authorizer != nil {
+		canvasService = api.NewAuthorizingCanvasService(canvasService, authorizer, cfg.Auth.AuthorizationCacheTTL)
+	}
+	canvasService = api.NewTracingCanvasService(canvasService)
+
 	server := &Server{
 		config:        cfg,
 		logger:        logger.WithValues("component", "apiserver"),
 		router:        nil,
 		kubeClient:    kubeClient,
 		clientset:     clientset,
-		canvasService: api.NewCanvasService(kubeClient),
+		authenticator: authenticator,
+		canvasService: canvasService,
+		rateLimiters:  newRateLimiters(cfg.RateLimit.Groups),
+		shutdown:      make(chan struct{}),
+	}
+	if cfg.IdempotencyKeyTTL > 0 {
+		server.idempotencyStore = NewMemoryIdempotencyStore(cfg.IdempotencyKeyTTL)
+	}
+	if cfg.Audit.Enabled {
+		if server.auditor, err = newAuditor(cfg.Audit, logger); err != nil {
+			return nil, err
+		}
 	}
 
-	server.setupRESTRouter()
-	return server
+	if authenticator == nil {
+		server.logger.Warn("Authentication is disabled, the API is open to anyone who can reach it")
+	}
+
+	if err := server.setupRESTRouter(); err != nil {
+		return nil, err
+	}
+	return server, nil
 }
 
-// Run starts the REST API server.
+// Run starts the REST API server and gracefully shuts it down once stopCh is
+// closed.
 func (s *Server) Run(stopCh <-chan struct{}) error {
-	s.logger.Info("Starting REST API server", "address", s.config.BindAddress)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if s.auditor != nil {
+		defer func() {
+			if err := s.auditor.Close(); err != nil {
+				s.logger.Error(err, "Failed to close audit file")
+			}
+		}()
+	}
 
 	srv := &http.Server{
-		Addr:    s.config.BindAddress,
-		Handler: s.router,
+		Addr:              s.config.BindAddress,
+		Handler:           s.router,
+		ReadHeaderTimeout: s.config.ReadHeaderTimeout,
+		ReadTimeout:       s.config.ReadTimeout,
+		WriteTimeout:      s.config.WriteTimeout,
+		IdleTimeout:       s.config.IdleTimeout,
+		MaxHeaderBytes:    s.config.MaxHeaderBytes,
 	}
+	srv.RegisterOnShutdown(func() {
+		close(s.shutdown)
+	})
 
+	metricsServer, err := metricsserver.NewServer(metricsserver.Options{
+		BindAddress: s.config.MetricsBindAddress,
+	}, nil, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create metrics server: %w", err)
+	}
+	if metricsServer != nil {
+		go func() {
+			if err := metricsServer.Start(ctx); err != nil {
+				s.logger.Error(err, "Metrics server failed")
+			}
+		}()
+	}
+
+	if s.config.TLS.Enabled() {
+		tlsConfig, err := newTLSConfig(ctx, s.config.TLS)
+		if err != nil {
+			return err
+		}
+		srv.TLSConfig = tlsConfig
+	}
+
+	listener, err := net.Listen("tcp", s.config.BindAddress)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", s.config.BindAddress, err)
+	}
+
+	s.logger.Info("Starting REST API server",
+		"address", listener.Addr().String(),
+		"tls", srv.TLSConfig != nil,
+		"clientCertificates", s.config.TLS.ClientCAFile != "",
+	)
+
+	errCh := make(chan error, 1)
 	go func() {
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			s.logger.Error(err, "REST API server failed")
+		if srv.TLSConfig != nil {
+			errCh <- srv.ServeTLS(listener, "", "")
+		} else {
+			errCh <- srv.Serve(listener)
 		}
 	}()
 
-	<-stopCh
-	s.logger.Info("Stopping REST API server")
-	return srv.Close()
+	select {
+	case err := <-errCh:
+		return fmt.Errorf("REST API server failed: %w", err)
+	case <-stopCh:
+	}
+
+	s.logger.Info("Stopping REST API server", "timeout", s.config.ShutdownTimeout)
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), s.config.ShutdownTimeout)
+	defer cancelShutdown()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		s.logger.Error(err, "REST API server did not drain in time, closing remaining connections")
+		return srv.Close()
+	}
+	return nil
 }
@@ -0,0 +1,139 @@
+package rest
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/certwatcher"
+)
+
+const (
+	// ClientAuthRequire rejects clients that do not present a certificate
+	// signed by the client CA.
+	ClientAuthRequire = "require"
+	// ClientAuthVerifyIfGiven verifies client certificates when presented
+	// but still accepts clients without one.
+	ClientAuthVerifyIfGiven = "verify-if-given"
+)
+
+// TLSConfig contains the options for serving the REST API over TLS.
+type TLSConfig struct {
+	// CertFile and KeyFile enable TLS when both are set. They are reloaded
+	// when they change, so rotated certificates are served without a restart.
+	CertFile string `env:"CERT_FILE"`
+	KeyFile  string `env:"KEY_FILE"`
+	// ClientCAFile enables client certificate verification against the CAs
+	// it contains. It is reloaded when it changes.
+	ClientCAFile string `env:"CLIENT_CA_FILE"`
+	// ClientAuth is either require or verify-if-given.
+	ClientAuth string `env:"CLIENT_AUTH" envDefault:"require"`
+}
+
+// Enabled reports whether the server must serve TLS.
+func (c TLSConfig) Enabled() bool {
+	return c.CertFile != "" || c.KeyFile != ""
+}
+
+// newTLSConfig builds the TLS configuration of the server. The certificate
+// watcher runs until ctx is done.
+func newTLSConfig(ctx context.Context, cfg TLSConfig) (*tls.Config, error) {
+	if cfg.CertFile == "" || cfg.KeyFile == "" {
+		return nil, fmt.Errorf("both a certificate and a key file are required to serve TLS")
+	}
+
+	watcher, err := certwatcher.New(cfg.CertFile, cfg.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load serving certificate: %w", err)
+	}
+	go func() {
+		_ = watcher.Start(ctx)
+	}()
+
+	tlsConfig := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: watcher.GetCertificate,
+		NextProtos:     []string{"h2", "http/1.1"},
+	}
+	if cfg.ClientCAFile == "" {
+		return tlsConfig, nil
+	}
+
+	switch cfg.ClientAuth {
+	case ClientAuthRequire, "":
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	case ClientAuthVerifyIfGiven:
+		tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+	default:
+		return nil, fmt.Errorf("unknown client auth mode %q", cfg.ClientAuth)
+	}
+
+	clientCAs := &clientCAPool{path: cfg.ClientCAFile}
+	if _, err := clientCAs.get(); err != nil {
+		return nil, err
+	}
+	tlsConfig.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		pool, err := clientCAs.get()
+		if err != nil {
+			return nil, err
+		}
+		config := tlsConfig.Clone()
+		config.GetConfigForClient = nil
+		config.ClientCAs = pool
+		return config, nil
+	}
+	return tlsConfig, nil
+}
+
+// clientCAPool loads the client CA bundle and reloads it whenever the file
+// changes.
+type clientCAPool struct {
+	path string
+
+	mu      sync.Mutex
+	modTime time.Time
+	size    int64
+	pool    *x509.CertPool
+}
+
+// get returns the current CA pool. When the file can no longer be read or
+// parsed, the last valid pool keeps being used.
+func (p *clientCAPool) get() (*x509.CertPool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	info, err := os.Stat(p.path)
+	if err != nil {
+		if p.pool != nil {
+			return p.pool, nil
+		}
+		return nil, fmt.Errorf("failed to read client CA file: %w", err)
+	}
+	if p.pool != nil && info.ModTime().Equal(p.modTime) && info.Size() == p.size {
+		return p.pool, nil
+	}
+
+	data, err := os.ReadFile(p.path)
+	if err != nil {
+		if p.pool != nil {
+			return p.pool, nil
+		}
+		return nil, fmt.Errorf("failed to read client CA file: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		if p.pool != nil {
+			return p.pool, nil
+		}
+		return nil, fmt.Errorf("client CA file %s contains no certificates", p.path)
+	}
+
+	p.pool = pool
+	p.modTime = info.ModTime()
+	p.size = info.Size()
+	return pool, nil
+}
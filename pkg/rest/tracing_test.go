@@ -0,0 +1,88 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/orray-proj/orray/api/v1alpha1"
+	"github.com/orray-proj/orray/pkg/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestTracing(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1alpha1.AddToScheme(scheme))
+
+	provider, propagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	t.Cleanup(func() {
+		otel.SetTracerProvider(provider)
+		otel.SetTextMapPropagator(propagator)
+	})
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	logger, _ := logging.NewLogger(logging.DebugLevel, logging.ConsoleFormat)
+	server, err := NewServer(
+		context.Background(), &Config{Mode: "test"}, logger,
+		fake.NewClientBuilder().WithScheme(scheme).Build(), nil,
+	)
+	require.NoError(t, err)
+
+	t.Run("ContinuesClientTrace", func(t *testing.T) {
+		recorder.Reset()
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/api/v1alpha1/canvases/missing", nil)
+		req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+		server.router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", w.Header().Get("X-Request-ID"))
+
+		spans := recorder.Ended()
+		require.Len(t, spans, 2)
+		service, route := spans[0], spans[1]
+		assert.Equal(t, "GET /api/v1alpha1/canvases/:name", route.Name())
+		assert.Equal(t, "00f067aa0ba902b7", route.Parent().SpanID().String())
+		assert.Equal(t, "CanvasService.Get", service.Name())
+		assert.Equal(t, route.SpanContext().SpanID(), service.Parent().SpanID())
+		assert.Equal(t, route.SpanContext().TraceID(), service.SpanContext().TraceID())
+	})
+
+	t.Run("RequestIDFromNewTrace", func(t *testing.T) {
+		recorder.Reset()
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1alpha1/canvases", nil))
+
+		spans := recorder.Ended()
+		require.NotEmpty(t, spans)
+		route := spans[len(spans)-1]
+		assert.False(t, route.Parent().IsValid())
+		assert.Equal(t, route.SpanContext().TraceID().String(), w.Header().Get("X-Request-ID"))
+	})
+
+	t.Run("ClientRequestID", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/api/v1alpha1/canvases", nil)
+		req.Header.Set("X-Request-ID", "client-id")
+		server.router.ServeHTTP(w, req)
+
+		assert.Equal(t, "client-id", w.Header().Get("X-Request-ID"))
+	})
+
+	t.Run("ProbesAreNotTraced", func(t *testing.T) {
+		recorder.Reset()
+		server.router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+		assert.Empty(t, recorder.Ended())
+	})
+}
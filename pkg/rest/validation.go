@@ -0,0 +1,120 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/orray-proj/orray/pkg/rest/dto"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// registerValidators makes the binding validator report fields by the names
+// clients use on the wire rather than by Go struct field names, and registers
+// the custom validation tags used by the DTOs.
+func registerValidators() {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return
+	}
+	v.RegisterTagNameFunc(wireFieldName)
+	_ = v.RegisterValidation("labelselector", func(fl validator.FieldLevel) bool {
+		_, err := labels.Parse(fl.Field().String())
+		return err == nil
+	})
+}
+
+// wireFieldName returns the JSON name of a struct field, falling back to its
+// form name for query parameters.
+func wireFieldName(field reflect.StructField) string {
+	for _, tag := range []string{"json", "form"} {
+		name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+// fieldErrorsFromBinding extracts field-level details from an error returned
+// while binding a request. Only the fields of the request body, as opposed to
+// its query parameters, are located with a JSONPath. It returns nil if the
+// error has no field context.
+func fieldErrorsFromBinding(err error, inBody bool) []dto.FieldError {
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		fieldErrors := make([]dto.FieldError, 0, len(validationErrs))
+		for _, fe := range validationErrs {
+			fieldError := dto.FieldError{
+				Field:   fe.Field(),
+				Tag:     fe.Tag(),
+				Param:   fe.Param(),
+				Message: validationMessage(fe),
+			}
+			if inBody {
+				fieldError.JSONPath = jsonPath(fe.Namespace())
+			}
+			fieldErrors = append(fieldErrors, fieldError)
+		}
+		return fieldErrors
+	}
+
+	// Only JSON bodies report type errors with a field.
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return []dto.FieldError{{
+			Field:    typeErr.Field[strings.LastIndex(typeErr.Field, ".")+1:],
+			JSONPath: "$." + typeErr.Field,
+			Tag:      "type",
+			Param:    typeErr.Type.String(),
+			Message:  fmt.Sprintf("%s must be of type %s", typeErr.Field, typeErr.Type),
+		}}
+	}
+
+	return nil
+}
+
+// jsonPath converts a validator namespace such as "CreateCanvasRequest.name"
+// into a JSONPath expression relative to the request, such as "$.name".
+func jsonPath(namespace string) string {
+	_, path, found := strings.Cut(namespace, ".")
+	if !found {
+		return "$"
+	}
+	return "$." + path
+}
+
+// validationMessage returns a human-readable message for a failed validation.
+func validationMessage(fe validator.FieldError) string {
+	isString := fe.Kind() == reflect.String
+	switch fe.Tag() {
+	case "required":
+		return fmt.Sprintf("%s is required", fe.Field())
+	case "min":
+		if isString {
+			return fmt.Sprintf("%s must be at least %s characters long", fe.Field(), fe.Param())
+		}
+		return fmt.Sprintf("%s must be at least %s", fe.Field(), fe.Param())
+	case "max":
+		if isString {
+			return fmt.Sprintf("%s must be at most %s characters long", fe.Field(), fe.Param())
+		}
+		return fmt.Sprintf("%s must be at most %s", fe.Field(), fe.Param())
+	case "excluded_unless":
+		other, value, _ := strings.Cut(fe.Param(), " ")
+		return fmt.Sprintf("%s cannot be set unless %s is %s", fe.Field(), strings.ToLower(other), value)
+	case "labelselector":
+		return fmt.Sprintf("%s must be a valid label selector", fe.Field())
+	case "oneof":
+		return fmt.Sprintf("%s must be one of: %s", fe.Field(), strings.ReplaceAll(fe.Param(), " ", ", "))
+	default:
+		return fmt.Sprintf("%s failed the %q validation", fe.Field(), fe.Tag())
+	}
+}
@@ -0,0 +1,139 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/orray-proj/orray/api/v1alpha1"
+	"github.com/orray-proj/orray/pkg/logging"
+	"github.com/orray-proj/orray/pkg/rest/dto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestFieldErrorsFromBinding(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	registerValidators()
+
+	t.Run("MissingBodyFields", func(t *testing.T) {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"displayName":"Test"}`))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		var req dto.CreateCanvasRequest
+		err := c.ShouldBindJSON(&req)
+
+		assert.Equal(t, []dto.FieldError{
+			{
+				Field:    "name",
+				JSONPath: "$.name",
+				Tag:      "required",
+				Message:  "name is required",
+			},
+		}, fieldErrorsFromBinding(err, true))
+	})
+
+	t.Run("WrongBodyType", func(t *testing.T) {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"test","displayName":1}`))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		var req dto.CreateCanvasRequest
+		err := c.ShouldBindJSON(&req)
+
+		fieldErrors := fieldErrorsFromBinding(err, true)
+		assert.Len(t, fieldErrors, 1)
+		assert.Equal(t, "displayName", fieldErrors[0].Field)
+		assert.Equal(t, "$.displayName", fieldErrors[0].JSONPath)
+		assert.Equal(t, "type", fieldErrors[0].Tag)
+		assert.Equal(t, "string", fieldErrors[0].Param)
+	})
+
+	t.Run("QueryParameterOutOfRange", func(t *testing.T) {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodGet, "/?limit=500", nil)
+
+		var req dto.PaginationRequest
+		err := c.ShouldBindQuery(&req)
+
+		assert.Equal(t, []dto.FieldError{
+			{
+				Field:   "limit",
+				Tag:     "max",
+				Param:   "100",
+				Message: "limit must be at most 100",
+			},
+		}, fieldErrorsFromBinding(err, false))
+	})
+
+	t.Run("ContinueWithOffset", func(t *testing.T) {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodGet, "/?offset=10&continue=abc", nil)
+
+		var req dto.PaginationRequest
+		err := c.ShouldBindQuery(&req)
+
+		assert.Equal(t, []dto.FieldError{
+			{
+				Field:   "continue",
+				Tag:     "excluded_unless",
+				Param:   "Offset 0",
+				Message: "continue cannot be set unless offset is 0",
+			},
+		}, fieldErrorsFromBinding(err, false))
+	})
+
+	t.Run("MalformedBody", func(t *testing.T) {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{`))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		var req dto.CreateCanvasRequest
+		err := c.ShouldBindJSON(&req)
+
+		assert.Error(t, err)
+		assert.Nil(t, fieldErrorsFromBinding(err, true))
+	})
+}
+
+func TestListCanvasesQueryErrors(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1alpha1.AddToScheme(scheme))
+
+	logger, _ := logging.NewLogger(logging.DebugLevel, logging.ConsoleFormat)
+	kubeClient := fake.NewClientBuilder().WithScheme(scheme).Build()
+	server, err := NewServer(context.Background(), &Config{Mode: "test"}, logger, kubeClient, nil)
+	require.NoError(t, err)
+
+	tests := []struct {
+		query string
+		field string
+	}{
+		{query: "limit=500", field: "limit"},
+		{query: "phase=Unknown", field: "phase"},
+		{query: "q=test&continue=abc", field: "continue"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			server.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1alpha1/canvases?"+tt.query, nil))
+			require.Equal(t, http.StatusBadRequest, w.Code)
+
+			var resp struct {
+				Details []dto.FieldError `json:"details"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			require.Len(t, resp.Details, 1)
+			assert.Equal(t, tt.field, resp.Details[0].Field)
+			// Query parameters are not located in the request body.
+			assert.Empty(t, resp.Details[0].JSONPath)
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package rest
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+	orrayv1alpha1 "github.com/orray-proj/orray/api/v1alpha1"
+	"github.com/orray-proj/orray/pkg/rest/dto"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// watchCanvasesV1alpha1 streams canvas changes to the client as Server-Sent
+// Events. Each event is named after the watch event type, carries the canvas
+// as its data and uses the resource version as its id, so clients can resume
+// with the Last-Event-ID header after a disconnect.
+func (s *Server) watchCanvasesV1alpha1(c *gin.Context, req dto.WatchRequest) {
+	resourceVersion := req.ResourceVersion
+	if resourceVersion == "" {
+		resourceVersion = c.GetHeader("Last-Event-ID")
+	}
+
+	ctx := c.Request.Context()
+	watcher, err := s.canvasService.Watch(ctx, resourceVersion)
+	if err != nil {
+		s.logKubernetesError(c, err, "failed to watch canvases", "resourceVersion", resourceVersion)
+		KubernetesError(c, err, "failed to watch canvases")
+		return
+	}
+	defer watcher.Stop()
+
+	// The stream outlives the server's write timeout by design.
+	if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{}); err != nil {
+		s.requestLogger(c).Debug("Failed to clear the write deadline of the watch stream", "error", err.Error())
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(s.config.WatchHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-s.shutdown:
+			return false
+		case <-heartbeat.C:
+			_, err := io.WriteString(w, ": heartbeat\n\n")
+			return err == nil
+		case event, ok := <-watcher.ResultChan():
+			if !ok {
+				return false
+			}
+			return s.writeCanvasEvent(c, w, event)
+		}
+	})
+}
+
+// writeCanvasEvent writes a single watch event to the stream. It returns false
+// when the stream must be closed.
+func (s *Server) writeCanvasEvent(c *gin.Context, w io.Writer, event watch.Event) bool {
+	switch event.Type {
+	case watch.Added, watch.Modified, watch.Deleted:
+		canvas, ok := event.Object.(*orrayv1alpha1.Canvas)
+		if !ok {
+			return true
+		}
+		c.Render(-1, sse.Event{
+			Id:    canvas.ResourceVersion,
+			Event: string(event.Type),
+			Data:  dto.CanvasFromV1Alpha1(canvas),
+		})
+		return true
+	case watch.Bookmark:
+		canvas, ok := event.Object.(*orrayv1alpha1.Canvas)
+		if !ok {
+			return true
+		}
+		// An id without data moves the client's Last-Event-ID forward
+		// without dispatching an event.
+		_, err := fmt.Fprintf(w, "id:%s\n\n", canvas.ResourceVersion)
+		return err == nil
+	case watch.Error:
+		err := apierrors.FromObject(event.Object)
+		s.logKubernetesError(c, err, "canvas watch failed")
+		_, resp, ok := kubernetesErrorResponse(c, err)
+		if !ok {
+			resp = dto.ErrorResponse{
+				Code:      "INTERNAL_SERVER_ERROR",
+				Message:   "canvas watch failed",
+				RequestID: c.GetString("requestId"),
+			}
+		}
+		c.Render(-1, sse.Event{Event: string(watch.Error), Data: resp})
+		return false
+	default:
+		return true
+	}
+}
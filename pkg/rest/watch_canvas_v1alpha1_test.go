@@ -0,0 +1,78 @@
+package rest
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/orray-proj/orray/api/v1alpha1"
+	"github.com/orray-proj/orray/pkg/logging"
+	"github.com/orray-proj/orray/pkg/rest/dto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestWatchCanvasesV1alpha1(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1alpha1.AddToScheme(scheme))
+
+	logger, _ := logging.NewLogger(logging.DebugLevel, logging.ConsoleFormat)
+	cl := fake.NewClientBuilder().WithScheme(scheme).Build()
+	cfg := &Config{Mode: "test", WatchHeartbeatInterval: time.Hour}
+	server, err := NewServer(context.Background(), cfg, logger, cl, nil)
+	require.NoError(t, err)
+
+	ts := httptest.NewServer(server.router)
+	defer ts.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL+"/api/v1alpha1/canvases?watch=true", nil)
+	require.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	require.NoError(t, cl.Create(ctx, &v1alpha1.Canvas{
+		ObjectMeta: metav1.ObjectMeta{Name: "test"},
+		Spec:       v1alpha1.CanvasSpec{DisplayName: "Test Canvas"},
+	}))
+
+	fields := map[string]string{}
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			break
+		}
+		key, value, _ := strings.Cut(line, ":")
+		fields[key] = value
+	}
+	require.NoError(t, scanner.Err())
+
+	assert.Equal(t, "ADDED", fields["event"])
+	assert.NotEmpty(t, fields["id"])
+
+	var canvas dto.Canvas
+	require.NoError(t, json.Unmarshal([]byte(fields["data"]), &canvas))
+	assert.Equal(t, "test", canvas.Name)
+	assert.Equal(t, "Test Canvas", canvas.DisplayName)
+
+	// Shutting the server down ends the stream.
+	close(server.shutdown)
+	_, err = io.Copy(io.Discard, resp.Body)
+	require.NoError(t, err)
+}
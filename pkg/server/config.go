@@ -4,12 +4,18 @@ import (
 	"fmt"
 
 	"github.com/caarlos0/env/v11"
+	"github.com/orray-proj/orray/pkg/tracing"
 )
 
 // Config contains the options for the server.
 type Config struct {
 	PprofBindAddress   string `env:"PPROF_BIND_ADDRESS" envDefault:""`
 	MetricsBindAddress string `env:"METRICS_BIND_ADDRESS" envDefault:"0"`
+	// OrrayNamespace is the namespace Orray is installed in.
+	OrrayNamespace string `env:"ORRAY_NAMESPACE" envDefault:"orray"`
+
+	// Tracing configures the export of spans to an OpenTelemetry collector.
+	Tracing tracing.Config
 }
 
 // NewConfig creates a new Config with the given environment variables.
@@ -17,5 +23,8 @@ func NewConfig(cfg *Config) error {
 	if err := env.Parse(cfg); err != nil {
 		return fmt.Errorf("failed to parse server config: %w", err)
 	}
+	if err := tracing.NewConfig(&cfg.Tracing); err != nil {
+		return err
+	}
 	return nil
 }
@@ -0,0 +1,37 @@
+package tracing
+
+import (
+	"fmt"
+
+	"github.com/caarlos0/env/v11"
+	"github.com/go-playground/validator/v10"
+)
+
+const (
+	ProtocolGRPC         = "grpc"
+	ProtocolHTTPProtobuf = "http/protobuf"
+)
+
+// Config is the configuration for the tracing package.
+//
+// Only the switch and the exporter protocol are read here. The OpenTelemetry
+// SDK reads the rest of its standard environment itself, such as
+// OTEL_EXPORTER_OTLP_ENDPOINT, OTEL_TRACES_SAMPLER, OTEL_TRACES_SAMPLER_ARG,
+// OTEL_SERVICE_NAME and OTEL_RESOURCE_ATTRIBUTES.
+type Config struct {
+	// Enabled turns on the export of spans to an OTLP collector.
+	Enabled bool `env:"TRACING_ENABLED" envDefault:"false"`
+	// Protocol is the OTLP transport used to reach the collector.
+	Protocol string `env:"OTEL_EXPORTER_OTLP_PROTOCOL" envDefault:"grpc" validate:"oneof=grpc http/protobuf"`
+}
+
+// NewConfig creates a new Config with the given environment variables.
+func NewConfig(cfg *Config) error {
+	if err := env.Parse(cfg); err != nil {
+		return fmt.Errorf("failed to parse tracing config: %w", err)
+	}
+	if err := validator.New().Struct(cfg); err != nil {
+		return fmt.Errorf("failed to validate tracing config: %w", err)
+	}
+	return nil
+}
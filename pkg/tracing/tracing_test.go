@@ -0,0 +1,195 @@
+package tracing
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	collectortracev1 "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/protobuf/proto"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+func TestNewConfig(t *testing.T) {
+	t.Run("Defaults", func(t *testing.T) {
+		cfg := Config{}
+		require.NoError(t, NewConfig(&cfg))
+		assert.Equal(t, Config{Enabled: false, Protocol: ProtocolGRPC}, cfg)
+	})
+
+	t.Run("HTTP", func(t *testing.T) {
+		t.Setenv("TRACING_ENABLED", "true")
+		t.Setenv("OTEL_EXPORTER_OTLP_PROTOCOL", "http/protobuf")
+
+		cfg := Config{}
+		require.NoError(t, NewConfig(&cfg))
+		assert.Equal(t, Config{Enabled: true, Protocol: ProtocolHTTPProtobuf}, cfg)
+	})
+
+	t.Run("UnsupportedProtocol", func(t *testing.T) {
+		t.Setenv("OTEL_EXPORTER_OTLP_PROTOCOL", "http/json")
+
+		assert.Error(t, NewConfig(&Config{}))
+	})
+}
+
+// restoreGlobals resets the global tracer provider and propagator once the
+// test is over.
+func restoreGlobals(t *testing.T) {
+	provider, propagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	t.Cleanup(func() {
+		otel.SetTracerProvider(provider)
+		otel.SetTextMapPropagator(propagator)
+	})
+}
+
+// collector is a stand-in for an OTLP/HTTP collector that keeps the export
+// requests it receives.
+type collector struct {
+	mu       sync.Mutex
+	requests []*collectortracev1.ExportTraceServiceRequest
+}
+
+func (c *collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/v1/traces" {
+		http.NotFound(w, r)
+		return
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	req := &collectortracev1.ExportTraceServiceRequest{}
+	if err := proto.Unmarshal(body, req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	c.mu.Lock()
+	c.requests = append(c.requests, req)
+	c.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/x-protobuf")
+	resp, _ := proto.Marshal(&collectortracev1.ExportTraceServiceResponse{})
+	_, _ = w.Write(resp)
+}
+
+func TestSetup(t *testing.T) {
+	t.Run("Disabled", func(t *testing.T) {
+		restoreGlobals(t)
+
+		shutdown, err := Setup(context.Background(), Config{}, "orray-test")
+		require.NoError(t, err)
+		assert.NoError(t, shutdown(context.Background()))
+
+		// The trace context of incoming requests is still propagated.
+		header := http.Header{}
+		header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+		ctx := otel.GetTextMapPropagator().Extract(context.Background(), propagation.HeaderCarrier(header))
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", trace.SpanContextFromContext(ctx).TraceID().String())
+	})
+
+	t.Run("ExportsToCollector", func(t *testing.T) {
+		restoreGlobals(t)
+
+		c := &collector{}
+		srv := httptest.NewServer(c)
+		defer srv.Close()
+		t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", srv.URL)
+		t.Setenv("OTEL_RESOURCE_ATTRIBUTES", "deployment.environment.name=test")
+
+		shutdown, err := Setup(context.Background(), Config{Enabled: true, Protocol: ProtocolHTTPProtobuf}, "orray-test")
+		require.NoError(t, err)
+
+		_, span := otel.Tracer("test").Start(context.Background(), "operation")
+		span.End()
+		require.NoError(t, shutdown(context.Background()))
+
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		require.Len(t, c.requests, 1)
+		resourceSpans := c.requests[0].ResourceSpans
+		require.Len(t, resourceSpans, 1)
+
+		attributes := map[string]string{}
+		for _, attribute := range resourceSpans[0].Resource.Attributes {
+			attributes[attribute.Key] = attribute.Value.GetStringValue()
+		}
+		assert.Equal(t, "orray-test", attributes["service.name"])
+		assert.Equal(t, "test", attributes["deployment.environment.name"])
+
+		require.Len(t, resourceSpans[0].ScopeSpans, 1)
+		require.Len(t, resourceSpans[0].ScopeSpans[0].Spans, 1)
+		assert.Equal(t, "operation", resourceSpans[0].ScopeSpans[0].Spans[0].Name)
+	})
+
+	t.Run("ServiceNameFromEnv", func(t *testing.T) {
+		restoreGlobals(t)
+
+		c := &collector{}
+		srv := httptest.NewServer(c)
+		defer srv.Close()
+		t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", srv.URL)
+		t.Setenv("OTEL_SERVICE_NAME", "custom")
+
+		shutdown, err := Setup(context.Background(), Config{Enabled: true, Protocol: ProtocolHTTPProtobuf}, "orray-test")
+		require.NoError(t, err)
+
+		_, span := otel.Tracer("test").Start(context.Background(), "operation")
+		span.End()
+		require.NoError(t, shutdown(context.Background()))
+
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		require.Len(t, c.requests, 1)
+		for _, attribute := range c.requests[0].ResourceSpans[0].Resource.Attributes {
+			if attribute.Key == "service.name" {
+				assert.Equal(t, "custom", attribute.Value.GetStringValue())
+			}
+		}
+	})
+}
+
+// recordingWebhookServer records the handlers registered on it.
+type recordingWebhookServer struct {
+	webhook.Server
+	handlers map[string]http.Handler
+}
+
+func (s *recordingWebhookServer) Register(path string, hook http.Handler) {
+	s.handlers[path] = hook
+}
+
+func TestNewWebhookServer(t *testing.T) {
+	restoreGlobals(t)
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	recording := &recordingWebhookServer{handlers: map[string]http.Handler{}}
+	var handlerSpan trace.SpanContext
+	NewWebhookServer(recording).Register("/validate", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handlerSpan = trace.SpanContextFromContext(r.Context())
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/validate", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	recording.handlers["/validate"].ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "admission /validate", spans[0].Name())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", spans[0].SpanContext().TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", spans[0].Parent().SpanID().String())
+	assert.Equal(t, spans[0].SpanContext().SpanID(), handlerSpan.SpanID())
+}
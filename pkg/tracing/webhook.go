@@ -0,0 +1,23 @@
+package tracing
+
+import (
+	"net/http"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+type webhookServer struct {
+	webhook.Server
+}
+
+// NewWebhookServer wraps server so that every admission request it handles is
+// traced, continuing the trace propagated by the Kubernetes API server.
+func NewWebhookServer(server webhook.Server) webhook.Server {
+	return &webhookServer{Server: server}
+}
+
+// Register implements webhook.Server.
+func (s *webhookServer) Register(path string, hook http.Handler) {
+	s.Server.Register(path, otelhttp.NewHandler(hook, "admission "+path))
+}
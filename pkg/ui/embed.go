@@ -1,15 +1,51 @@
 package ui
 
 import (
+	"crypto/sha256"
 	"embed"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
 	"io/fs"
+	"mime"
 	"net/http"
+	"path"
+	"regexp"
+	"strconv"
 	"strings"
+	"time"
 )
 
 //go:embed all:dist
 var distFS embed.FS
 
+const (
+	indexFile = "index.html"
+	// assetsDir holds the files emitted by the bundler, whose names contain a
+	// content hash and can therefore be cached forever.
+	assetsDir = "assets/"
+
+	immutableCacheControl = "public, max-age=31536000, immutable"
+	// revalidateCacheControl makes browsers check the ETag before reusing a
+	// file whose name does not change between releases.
+	revalidateCacheControl = "no-cache"
+)
+
+// encodings lists the pre-compressed variants looked up for each file, in
+// order of preference.
+var encodings = []struct {
+	name      string
+	extension string
+}{
+	{name: "br", extension: ".br"},
+	{name: "gzip", extension: ".gz"},
+}
+
+// inlineScriptPattern matches the inline scripts of index.html, which the
+// content security policy must allow by hash.
+var inlineScriptPattern = regexp.MustCompile(`(?s)<script>(.*?)</script>`)
+
 // Handler returns an http.Handler that serves the embedded UI assets.
 // It falls back to index.html for SPA client-side routing.
 func Handler() http.Handler {
@@ -17,25 +53,158 @@ func Handler() http.Handler {
 	if err != nil {
 		panic("failed to create sub filesystem for ui/dist: " + err.Error())
 	}
+	return handler(dist)
+}
+
+type uiHandler struct {
+	dist fs.FS
+	// etags holds the ETag of every file that is not served with an immutable
+	// cache policy.
+	etags map[string]string
+	csp   string
+}
 
-	fileServer := http.FileServer(http.FS(dist))
+func handler(dist fs.FS) http.Handler {
+	h := &uiHandler{
+		dist:  dist,
+		etags: map[string]string{},
+	}
 
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Try to serve the file directly
-		path := strings.TrimPrefix(r.URL.Path, "/")
-		if path == "" {
-			path = "index.html"
+	_ = fs.WalkDir(dist, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || strings.HasPrefix(name, assetsDir) {
+			return err
+		}
+		data, err := fs.ReadFile(dist, name)
+		if err != nil {
+			return err
 		}
+		sum := sha256.Sum256(data)
+		h.etags[name] = `"` + hex.EncodeToString(sum[:16]) + `"`
+		return nil
+	})
 
-		// Check if the file exists
-		if f, err := dist.Open(path); err == nil {
-			_ = f.Close()
-			fileServer.ServeHTTP(w, r)
+	index, _ := fs.ReadFile(dist, indexFile)
+	h.csp = contentSecurityPolicy(index)
+	return h
+}
+
+// contentSecurityPolicy builds a policy compatible with the React app: scripts
+// are only loaded from the same origin or inlined in index, styles may be
+// inlined by components, and the API is reached on the same origin.
+func contentSecurityPolicy(index []byte) string {
+	scriptSources := []string{"'self'"}
+	for _, match := range inlineScriptPattern.FindAllSubmatch(index, -1) {
+		sum := sha256.Sum256(match[1])
+		scriptSources = append(scriptSources, fmt.Sprintf("'sha256-%s'", base64.StdEncoding.EncodeToString(sum[:])))
+	}
+
+	return strings.Join([]string{
+		"default-src 'self'",
+		"script-src " + strings.Join(scriptSources, " "),
+		"style-src 'self' 'unsafe-inline'",
+		"img-src 'self' data: blob:",
+		"font-src 'self' data:",
+		"connect-src 'self'",
+		"object-src 'none'",
+		"base-uri 'self'",
+		"form-action 'self'",
+		"frame-ancestors 'none'",
+	}, "; ")
+}
+
+// ServeHTTP implements http.Handler.
+func (h *uiHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	name := strings.TrimPrefix(path.Clean("/"+r.URL.Path), "/")
+	if name == "" {
+		name = indexFile
+	}
+
+	if !h.exists(name) {
+		// Missing bundles must not be answered with index.html, which the
+		// browser would fail to parse as a script or stylesheet.
+		if strings.HasPrefix(name, assetsDir) {
+			http.NotFound(w, r)
 			return
 		}
-
 		// SPA fallback: serve index.html for client-side routing
-		r.URL.Path = "/"
-		fileServer.ServeHTTP(w, r)
-	})
+		name = indexFile
+	}
+
+	header := w.Header()
+	header.Set("Content-Security-Policy", h.csp)
+	if contentType := mime.TypeByExtension(path.Ext(name)); contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+
+	// The response may differ by encoding, so caches must tell them apart.
+	header.Set("Vary", "Accept-Encoding")
+	file := name
+	for _, encoding := range encodings {
+		if file == name && h.exists(name+encoding.extension) && acceptsEncoding(r, encoding.name) {
+			file = name + encoding.extension
+			header.Set("Content-Encoding", encoding.name)
+		}
+	}
+
+	if strings.HasPrefix(name, assetsDir) {
+		header.Set("Cache-Control", immutableCacheControl)
+	} else {
+		header.Set("Cache-Control", revalidateCacheControl)
+		// Each encoding is a distinct representation with its own ETag.
+		if etag, ok := h.etags[file]; ok {
+			header.Set("ETag", etag)
+		}
+	}
+
+	h.serveFile(w, r, file)
+}
+
+// exists reports whether name is a regular file of the embedded UI.
+func (h *uiHandler) exists(name string) bool {
+	info, err := fs.Stat(h.dist, name)
+	return err == nil && !info.IsDir()
+}
+
+// serveFile writes the content of file, honouring conditional and range
+// requests.
+func (h *uiHandler) serveFile(w http.ResponseWriter, r *http.Request, file string) {
+	f, err := h.dist.Open(file)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	defer func() { _ = f.Close() }()
+
+	content, ok := f.(io.ReadSeeker)
+	if !ok {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	// Embedded files have no modification time, so caching relies on ETags.
+	http.ServeContent(w, r, file, time.Time{}, content)
+}
+
+// acceptsEncoding reports whether the request accepts the given content coding.
+func acceptsEncoding(r *http.Request, encoding string) bool {
+	for _, value := range r.Header.Values("Accept-Encoding") {
+		for _, part := range strings.Split(value, ",") {
+			coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+			if !strings.EqualFold(strings.TrimSpace(coding), encoding) {
+				continue
+			}
+			param, weight, _ := strings.Cut(strings.TrimSpace(params), "=")
+			if strings.TrimSpace(param) != "q" {
+				return true
+			}
+			quality, err := strconv.ParseFloat(strings.TrimSpace(weight), 64)
+			return err == nil && quality > 0
+		}
+	}
+	return false
 }
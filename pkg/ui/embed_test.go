@@ -0,0 +1,118 @@
+package ui
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler(t *testing.T) {
+	const inlineScript = `document.documentElement.classList.add("dark");`
+
+	dist := fstest.MapFS{
+		"index.html":             {Data: []byte("<html><script>" + inlineScript + "</script></html>")},
+		"index.html.gz":          {Data: []byte("gzip index")},
+		"favicon.svg":            {Data: []byte("<svg/>")},
+		"assets/index-abc.js":    {Data: []byte("console.log(1)")},
+		"assets/index-abc.js.br": {Data: []byte("brotli js")},
+		"assets/index-abc.js.gz": {Data: []byte("gzip js")},
+	}
+	h := handler(dist)
+
+	serve := func(method, target string, headers map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, nil)
+		for key, value := range headers {
+			req.Header.Set(key, value)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Index", func(t *testing.T) {
+		w := serve(http.MethodGet, "/", nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), inlineScript)
+		assert.Equal(t, "text/html; charset=utf-8", w.Header().Get("Content-Type"))
+		assert.Equal(t, "no-cache", w.Header().Get("Cache-Control"))
+		assert.NotEmpty(t, w.Header().Get("ETag"))
+		assert.Empty(t, w.Header().Get("Content-Encoding"))
+		assert.Equal(t, "Accept-Encoding", w.Header().Get("Vary"))
+	})
+
+	t.Run("SPAFallback", func(t *testing.T) {
+		w := serve(http.MethodGet, "/canvases/test", nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), inlineScript)
+		assert.Equal(t, "no-cache", w.Header().Get("Cache-Control"))
+	})
+
+	t.Run("NotModified", func(t *testing.T) {
+		etag := serve(http.MethodGet, "/favicon.svg", nil).Header().Get("ETag")
+
+		w := serve(http.MethodGet, "/favicon.svg", map[string]string{"If-None-Match": etag})
+
+		assert.Equal(t, http.StatusNotModified, w.Code)
+	})
+
+	t.Run("HashedAsset", func(t *testing.T) {
+		w := serve(http.MethodGet, "/assets/index-abc.js", nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "console.log(1)", w.Body.String())
+		assert.Equal(t, "public, max-age=31536000, immutable", w.Header().Get("Cache-Control"))
+		assert.Contains(t, w.Header().Get("Content-Type"), "javascript")
+	})
+
+	t.Run("MissingAsset", func(t *testing.T) {
+		w := serve(http.MethodGet, "/assets/missing.js", nil)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("Brotli", func(t *testing.T) {
+		w := serve(http.MethodGet, "/assets/index-abc.js", map[string]string{"Accept-Encoding": "gzip, deflate, br"})
+
+		assert.Equal(t, "brotli js", w.Body.String())
+		assert.Equal(t, "br", w.Header().Get("Content-Encoding"))
+		assert.Contains(t, w.Header().Get("Content-Type"), "javascript")
+		assert.Equal(t, []string{"Accept-Encoding"}, w.Header().Values("Vary"))
+	})
+
+	t.Run("Gzip", func(t *testing.T) {
+		w := serve(http.MethodGet, "/assets/index-abc.js", map[string]string{"Accept-Encoding": "gzip, br;q=0"})
+
+		assert.Equal(t, "gzip js", w.Body.String())
+		assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+	})
+
+	t.Run("CompressedIndexHasOwnETag", func(t *testing.T) {
+		plain := serve(http.MethodGet, "/", nil)
+		compressed := serve(http.MethodGet, "/", map[string]string{"Accept-Encoding": "gzip"})
+
+		assert.Equal(t, "gzip index", compressed.Body.String())
+		assert.NotEqual(t, plain.Header().Get("ETag"), compressed.Header().Get("ETag"))
+	})
+
+	t.Run("ContentSecurityPolicy", func(t *testing.T) {
+		csp := serve(http.MethodGet, "/", nil).Header().Get("Content-Security-Policy")
+
+		sum := sha256.Sum256([]byte(inlineScript))
+		assert.Contains(t, csp, "script-src 'self' 'sha256-"+base64.StdEncoding.EncodeToString(sum[:])+"';")
+		assert.Contains(t, csp, "style-src 'self' 'unsafe-inline'")
+		assert.Contains(t, csp, "frame-ancestors 'none'")
+	})
+
+	t.Run("MethodNotAllowed", func(t *testing.T) {
+		w := serve(http.MethodPost, "/", nil)
+
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	})
+}
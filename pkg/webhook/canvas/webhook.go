@@ -2,23 +2,41 @@ package canvas
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"slices"
+	"strings"
 
 	"github.com/orray-proj/orray/api/v1alpha1"
+	"github.com/orray-proj/orray/pkg/kubernetes"
 	"github.com/orray-proj/orray/pkg/logging"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 )
 
 // CanvasWebhook implements the Defaulter and Validator interfaces.
 type CanvasWebhook struct {
 	Logger *logging.Logger
+	// Reader reads the namespaces of canvases and the pods running in them.
+	Reader client.Reader
+	// OrrayNamespace is the namespace Orray is installed in, which canvases
+	// may not take over.
+	OrrayNamespace string
 }
 
 // NewCanvasWebhook returns a new CanvasWebhook.
-func NewCanvasWebhook(logger *logging.Logger) *CanvasWebhook {
+func NewCanvasWebhook(logger *logging.Logger, reader client.Reader, orrayNamespace string) *CanvasWebhook {
 	return &CanvasWebhook{
-		Logger: logger,
+		Logger:         logger,
+		Reader:         reader,
+		OrrayNamespace: orrayNamespace,
 	}
 }
 
@@ -32,7 +50,7 @@ func (w *CanvasWebhook) SetupWebhookWithManager(mgr ctrl.Manager) error {
 
 // Default implements admission.CustomDefaulter so a webhook will be registered for the type
 func (w *CanvasWebhook) Default(ctx context.Context, canvas *v1alpha1.Canvas) error {
-	w.Logger.Debug("defaulting canvas", "name", canvas.Name)
+	w.Logger.WithContext(ctx).Debug("defaulting canvas", "name", canvas.Name)
 
 	if canvas.Spec.DisplayName == "" {
 		canvas.Spec.DisplayName = canvas.Name
@@ -43,28 +61,197 @@ func (w *CanvasWebhook) Default(ctx context.Context, canvas *v1alpha1.Canvas) er
 
 // ValidateCreate implements admission.CustomValidator so a webhook will be registered for the type
 func (w *CanvasWebhook) ValidateCreate(ctx context.Context, canvas *v1alpha1.Canvas) (admission.Warnings, error) {
-	w.Logger.Debug("validate create canvas", "name", canvas.Name)
+	w.Logger.WithContext(ctx).Debug("validate create canvas", "name", canvas.Name)
 
-	return nil, w.validateCanvas(canvas)
+	if err := w.validateCanvas(canvas); err != nil {
+		return nil, err
+	}
+	return nil, w.validateHomeNamespace(ctx, canvas)
 }
 
 // ValidateUpdate implements admission.CustomValidator so a webhook will be registered for the type
 func (w *CanvasWebhook) ValidateUpdate(
 	ctx context.Context, oldObj, newObj *v1alpha1.Canvas,
 ) (admission.Warnings, error) {
-	w.Logger.Debug("validate update canvas", "name", newObj.Name)
+	w.Logger.WithContext(ctx).Debug("validate update canvas", "name", newObj.Name)
 
+	if oldObj.HomeNamespace() != newObj.HomeNamespace() {
+		return nil, apierrors.NewInvalid(v1alpha1.GroupVersion.WithKind("Canvas").GroupKind(), newObj.Name,
+			field.ErrorList{field.Invalid(field.NewPath("spec", "homeNamespace"), newObj.Spec.HomeNamespace,
+				"home namespace cannot be changed")})
+	}
 	return nil, w.validateCanvas(newObj)
 }
 
 // ValidateDelete implements admission.CustomValidator so a webhook will be registered for the type
 func (w *CanvasWebhook) ValidateDelete(ctx context.Context, canvas *v1alpha1.Canvas) (admission.Warnings, error) {
-	return nil, nil
+	w.Logger.WithContext(ctx).Debug("validate delete canvas", "name", canvas.Name)
+
+	if err := validatePropagationPolicy(ctx, canvas); err != nil {
+		return nil, err
+	}
+	return nil, w.validateDeletionProtection(ctx, canvas)
 }
 
 func (w *CanvasWebhook) validateCanvas(canvas *v1alpha1.Canvas) error {
+	var errs field.ErrorList
 	if canvas.Spec.DisplayName == "" {
-		return fmt.Errorf("spec.displayName is required")
+		errs = append(errs, field.Required(field.NewPath("spec", "displayName"), "display name is required"))
+	}
+	if canvas.Spec.HomeNamespace != "" {
+		errs = append(errs, validateNames([]string{canvas.Spec.HomeNamespace}, field.NewPath("spec", "homeNamespace"))...)
+	}
+	if w.isProtected(canvas.HomeNamespace()) {
+		path := field.NewPath("spec", "homeNamespace")
+		if canvas.Spec.HomeNamespace == "" {
+			path = field.NewPath("metadata", "name")
+		}
+		errs = append(errs, field.Forbidden(path,
+			fmt.Sprintf("namespace %s is reserved and cannot be used by a canvas", canvas.HomeNamespace())))
+	}
+	errs = append(errs, validateNamespaces(canvas.Spec.Namespaces, field.NewPath("spec", "namespaces"))...)
+	errs = append(errs, validateMembers(canvas.Spec.Members, field.NewPath("spec", "members"))...)
+	if network := canvas.Spec.Network; network != nil {
+		path := field.NewPath("spec", "network")
+		errs = append(errs, validateNames(network.AllowedCanvases, path.Child("allowedCanvases"))...)
+		errs = append(errs, validateNames(network.AllowedNamespaces, path.Child("allowedNamespaces"))...)
+	}
+	if len(errs) > 0 {
+		return apierrors.NewInvalid(v1alpha1.GroupVersion.WithKind("Canvas").GroupKind(), canvas.Name, errs)
+	}
+	return nil
+}
+
+// validateHomeNamespace checks that the home namespace set on canvas either
+// does not exist yet or was annotated for canvas by whoever manages it.
+func (w *CanvasWebhook) validateHomeNamespace(ctx context.Context, canvas *v1alpha1.Canvas) error {
+	if canvas.Spec.HomeNamespace == "" {
+		return nil
+	}
+	ns := &corev1.Namespace{}
+	err := w.Reader.Get(ctx, client.ObjectKey{Name: canvas.Spec.HomeNamespace}, ns)
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return apierrors.NewInternalError(fmt.Errorf("failed to get namespace %s: %w", canvas.Spec.HomeNamespace, err))
+	}
+	if ns.Annotations[v1alpha1.AnnotationCanvas] == canvas.Name {
+		return nil
+	}
+	return apierrors.NewInvalid(v1alpha1.GroupVersion.WithKind("Canvas").GroupKind(), canvas.Name,
+		field.ErrorList{field.Forbidden(field.NewPath("spec", "homeNamespace"), fmt.Sprintf(
+			"namespace %s already exists and is not annotated with %s=%s",
+			ns.Name, v1alpha1.AnnotationCanvas, canvas.Name))})
+}
+
+// validatePropagationPolicy rejects the foreground deletion of a canvas
+// keeping its namespaces, since the garbage collector would delete the
+// namespaces it owns before they are released.
+func validatePropagationPolicy(ctx context.Context, canvas *v1alpha1.Canvas) error {
+	if canvas.Spec.DeletionPolicy != v1alpha1.DeletionPolicyOrphan &&
+		canvas.Spec.DeletionPolicy != v1alpha1.DeletionPolicyRetain {
+		return nil
+	}
+	req, err := admission.RequestFromContext(ctx)
+	if err != nil || len(req.Options.Raw) == 0 {
+		return nil
+	}
+	options := &metav1.DeleteOptions{}
+	if err := json.Unmarshal(req.Options.Raw, options); err != nil {
+		return apierrors.NewBadRequest(fmt.Sprintf("invalid delete options: %v", err))
+	}
+	if options.PropagationPolicy == nil || *options.PropagationPolicy != metav1.DeletePropagationForeground {
+		return nil
+	}
+	return apierrors.NewForbidden(v1alpha1.GroupVersion.WithResource("canvases").GroupResource(), canvas.Name,
+		fmt.Errorf("foreground deletion is not supported with deletion policy %s", canvas.Spec.DeletionPolicy))
+}
+
+// validateDeletionProtection rejects the deletion of a canvas annotated with
+// v1alpha1.AnnotationDeletionProtection while pods are pending or running in
+// the namespaces it owns.
+func (w *CanvasWebhook) validateDeletionProtection(ctx context.Context, canvas *v1alpha1.Canvas) error {
+	if canvas.Annotations[v1alpha1.AnnotationDeletionProtection] != "true" {
+		return nil
+	}
+	namespaces := append([]string{canvas.HomeNamespace()}, canvas.Status.Namespaces...)
+	slices.Sort(namespaces)
+	for _, name := range slices.Compact(namespaces) {
+		ns := &corev1.Namespace{}
+		if err := w.Reader.Get(ctx, client.ObjectKey{Name: name}, ns); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return apierrors.NewInternalError(fmt.Errorf("failed to get namespace %s: %w", name, err))
+		}
+		if !metav1.IsControlledBy(ns, canvas) {
+			continue
+		}
+		active, err := kubernetes.HasActivePods(ctx, w.Reader, name)
+		if err != nil {
+			return apierrors.NewInternalError(fmt.Errorf("failed to list pods in namespace %s: %w", name, err))
+		}
+		if active {
+			return apierrors.NewForbidden(v1alpha1.GroupVersion.WithResource("canvases").GroupResource(), canvas.Name,
+				fmt.Errorf("pods are running in namespace %s and %s is set", name, v1alpha1.AnnotationDeletionProtection))
+		}
 	}
 	return nil
 }
+
+// isProtected reports whether namespace belongs to the system or to Orray, so
+// that no canvas may take it over.
+func (w *CanvasWebhook) isProtected(namespace string) bool {
+	return namespace == metav1.NamespaceDefault ||
+		strings.HasPrefix(namespace, "kube-") ||
+		namespace == w.OrrayNamespace
+}
+
+func validateNamespaces(namespaces []v1alpha1.CanvasNamespace, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	for i, ns := range namespaces {
+		switch {
+		case (ns.Name == "") == (ns.Selector == nil):
+			errs = append(errs, field.Invalid(path.Index(i), ns, "exactly one of name or selector must be set"))
+		case ns.Selector != nil:
+			errs = append(errs, metav1validation.ValidateLabelSelector(ns.Selector,
+				metav1validation.LabelSelectorValidationOptions{}, path.Index(i).Child("selector"))...)
+		default:
+			for _, msg := range validation.IsDNS1123Label(ns.Name) {
+				errs = append(errs, field.Invalid(path.Index(i).Child("name"), ns.Name, msg))
+			}
+		}
+	}
+	return errs
+}
+
+func validateMembers(members []v1alpha1.CanvasMember, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	seen := make(map[v1alpha1.CanvasMember]bool, len(members))
+	for i, member := range members {
+		if member.Namespace != "" && member.Kind != v1alpha1.MemberKindServiceAccount {
+			errs = append(errs, field.Forbidden(path.Index(i).Child("namespace"),
+				"namespace may only be set for ServiceAccount members"))
+		}
+		// A member may only have one role.
+		key := member
+		key.Role = ""
+		if seen[key] {
+			errs = append(errs, field.Duplicate(path.Index(i), member.Name))
+		}
+		seen[key] = true
+	}
+	return errs
+}
+
+// validateNames checks that names are valid namespace names.
+func validateNames(names []string, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	for i, name := range names {
+		for _, msg := range validation.IsDNS1123Label(name) {
+			errs = append(errs, field.Invalid(path.Index(i), name, msg))
+		}
+	}
+	return errs
+}
@@ -0,0 +1,211 @@
+package canvas
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/orray-proj/orray/api/v1alpha1"
+	"github.com/orray-proj/orray/pkg/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func TestValidateCreateProtectedNamespaces(t *testing.T) {
+	logger, _ := logging.NewLogger(logging.DebugLevel, logging.ConsoleFormat)
+	w := NewCanvasWebhook(logger, fake.NewClientBuilder().Build(), "orray")
+
+	tests := []struct {
+		name          string
+		canvasName    string
+		homeNamespace string
+		wantErr       bool
+	}{
+		{name: "Default", canvasName: "default", wantErr: true},
+		{name: "KubeSystem", canvasName: "test", homeNamespace: "kube-system", wantErr: true},
+		{name: "KubePublic", canvasName: "kube-public", wantErr: true},
+		{name: "OrrayNamespace", canvasName: "test", homeNamespace: "orray", wantErr: true},
+		{name: "Allowed", canvasName: "test", homeNamespace: "payments"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			canvas := &v1alpha1.Canvas{
+				ObjectMeta: metav1.ObjectMeta{Name: tt.canvasName},
+				Spec: v1alpha1.CanvasSpec{
+					DisplayName:   "Test",
+					HomeNamespace: tt.homeNamespace,
+				},
+			}
+			_, err := w.ValidateCreate(context.Background(), canvas)
+			if tt.wantErr {
+				assert.ErrorContains(t, err, "is reserved")
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateCreateHomeNamespace(t *testing.T) {
+	logger, _ := logging.NewLogger(logging.DebugLevel, logging.ConsoleFormat)
+	cl := fake.NewClientBuilder().WithObjects(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "unmarked"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+			Name:        "marked",
+			Annotations: map[string]string{v1alpha1.AnnotationCanvas: "test"},
+		}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+			Name:        "other",
+			Annotations: map[string]string{v1alpha1.AnnotationCanvas: "other"},
+		}},
+	).Build()
+	w := NewCanvasWebhook(logger, cl, "orray")
+
+	tests := []struct {
+		name          string
+		homeNamespace string
+		wantErr       bool
+	}{
+		{name: "Missing", homeNamespace: "payments"},
+		{name: "Annotated", homeNamespace: "marked"},
+		{name: "NotAnnotated", homeNamespace: "unmarked", wantErr: true},
+		{name: "AnnotatedForOtherCanvas", homeNamespace: "other", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			canvas := &v1alpha1.Canvas{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec: v1alpha1.CanvasSpec{
+					DisplayName:   "Test",
+					HomeNamespace: tt.homeNamespace,
+				},
+			}
+			_, err := w.ValidateCreate(context.Background(), canvas)
+			if tt.wantErr {
+				assert.ErrorContains(t, err, "already exists")
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateDeleteProtected(t *testing.T) {
+	logger, _ := logging.NewLogger(logging.DebugLevel, logging.ConsoleFormat)
+	canvas := &v1alpha1.Canvas{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "test",
+			UID:         "canvas-uid",
+			Annotations: map[string]string{v1alpha1.AnnotationDeletionProtection: "true"},
+		},
+		Spec:   v1alpha1.CanvasSpec{DisplayName: "Test"},
+		Status: v1alpha1.CanvasStatus{Namespaces: []string{"test", "spanned"}},
+	}
+	isController := true
+	owned := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+		Name: "test",
+		OwnerReferences: []metav1.OwnerReference{{
+			APIVersion: v1alpha1.GroupVersion.String(),
+			Kind:       "Canvas",
+			Name:       canvas.Name,
+			UID:        canvas.UID,
+			Controller: &isController,
+		}},
+	}}
+	spanned := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "spanned"}}
+	pod := func(namespace string, phase corev1.PodPhase) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: namespace},
+			Status:     corev1.PodStatus{Phase: phase},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		pods    []*corev1.Pod
+		wantErr bool
+	}{
+		{name: "NoPods"},
+		{name: "RunningPods", pods: []*corev1.Pod{pod("test", corev1.PodRunning)}, wantErr: true},
+		{name: "PendingPods", pods: []*corev1.Pod{pod("test", corev1.PodPending)}, wantErr: true},
+		{name: "SucceededPods", pods: []*corev1.Pod{pod("test", corev1.PodSucceeded)}},
+		{name: "SpannedNamespace", pods: []*corev1.Pod{pod("spanned", corev1.PodRunning)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			builder := fake.NewClientBuilder().
+				WithObjects(owned, spanned).
+				WithIndex(&corev1.Pod{}, "status.phase", func(obj client.Object) []string {
+					return []string{string(obj.(*corev1.Pod).Status.Phase)}
+				})
+			for _, p := range tt.pods {
+				builder = builder.WithObjects(p)
+			}
+			w := NewCanvasWebhook(logger, builder.Build(), "orray")
+
+			_, err := w.ValidateDelete(context.Background(), canvas)
+			if tt.wantErr {
+				assert.True(t, apierrors.IsForbidden(err))
+				assert.ErrorContains(t, err, "pods are running in namespace test")
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateDeleteForeground(t *testing.T) {
+	logger, _ := logging.NewLogger(logging.DebugLevel, logging.ConsoleFormat)
+	w := NewCanvasWebhook(logger, fake.NewClientBuilder().Build(), "orray")
+
+	tests := []struct {
+		name        string
+		policy      v1alpha1.DeletionPolicy
+		propagation metav1.DeletionPropagation
+		wantErr     bool
+	}{
+		{name: "DeleteForeground", policy: v1alpha1.DeletionPolicyDelete, propagation: metav1.DeletePropagationForeground},
+		{name: "OrphanBackground", policy: v1alpha1.DeletionPolicyOrphan, propagation: metav1.DeletePropagationBackground},
+		{
+			name:        "OrphanForeground",
+			policy:      v1alpha1.DeletionPolicyOrphan,
+			propagation: metav1.DeletePropagationForeground,
+			wantErr:     true,
+		},
+		{
+			name:        "RetainForeground",
+			policy:      v1alpha1.DeletionPolicyRetain,
+			propagation: metav1.DeletePropagationForeground,
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			canvas := &v1alpha1.Canvas{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec:       v1alpha1.CanvasSpec{DisplayName: "Test", DeletionPolicy: tt.policy},
+			}
+			options, err := json.Marshal(&metav1.DeleteOptions{PropagationPolicy: &tt.propagation})
+			require.NoError(t, err)
+			ctx := admission.NewContextWithRequest(context.Background(), admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{Options: runtime.RawExtension{Raw: options}},
+			})
+
+			_, err = w.ValidateDelete(ctx, canvas)
+			if tt.wantErr {
+				assert.True(t, apierrors.IsForbidden(err))
+				assert.ErrorContains(t, err, "foreground deletion is not supported")
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}